@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"online-judge/internal/accounts"
+	"online-judge/internal/auth"
+	"online-judge/internal/config"
+	"online-judge/internal/database"
+	"online-judge/internal/store"
+)
+
+func main() {
+	// Parse command line flags
+	configPath := flag.String("config", "config.yaml", "path to config file")
+	username := flag.String("username", "", "user to promote, or to create as a new admin")
+	email := flag.String("email", "", "email of a new admin")
+	fullName := flag.String("full-name", "", "full name of a new admin")
+	flag.Parse()
+
+	if *username == "" {
+		log.Fatal("-username is required")
+	}
+
+	// Load configuration
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+
+	db, err := database.NewDB(database.ConfigFrom(cfg.Database))
+	if err != nil {
+		log.Fatalf("Error connecting to database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	users := store.NewUserStore(db)
+
+	// An existing user only needs the admin role
+	_, err = accounts.Promote(ctx, users, *username)
+	switch {
+	case err == nil:
+		log.Printf("Promoted %s to admin", *username)
+		return
+	case errors.Is(err, accounts.ErrAlreadyAdmin):
+		log.Printf("%s already is an admin", *username)
+		return
+	case !errors.Is(err, store.ErrNotFound):
+		log.Fatalf("Error promoting %s: %v", *username, err)
+	}
+
+	if *email == "" {
+		log.Fatalf("There is no user %s; pass -email to create it", *username)
+	}
+	password, err := readPassword(os.Stdin)
+	if err != nil {
+		log.Fatalf("Error reading password: %v", err)
+	}
+
+	passwords, err := auth.NewPasswordHasher(cfg.Server.BcryptCost)
+	if err != nil {
+		log.Fatalf("Error configuring password hashing: %v", err)
+	}
+	_, err = accounts.CreateAdmin(ctx, users, passwords, accounts.NewAdmin{
+		Username: *username,
+		Email:    *email,
+		FullName: *fullName,
+		Password: password,
+	})
+	if err != nil {
+		log.Fatalf("Error creating admin %s: %v", *username, err)
+	}
+	log.Printf("Created admin %s", *username)
+}
+
+// readPassword prompts twice for the password when stdin is a terminal, and
+// reads it from the first line of stdin otherwise.
+func readPassword(stdin *os.File) (string, error) {
+	fd := int(stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprint(os.Stderr, "Confirm password: ")
+	confirmation, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if string(password) != string(confirmation) {
+		return "", errors.New("passwords do not match")
+	}
+	return string(password), nil
+}
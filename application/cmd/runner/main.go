@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"expvar"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"online-judge/internal/config"
+	"online-judge/internal/queue"
+	"online-judge/internal/runner"
+)
+
+func main() {
+	// Parse command line flags
+	configPath := flag.String("config", "config.yaml", "path to config file")
+	workerID := flag.String("worker-id", defaultWorkerID(), "unique name of this runner, recorded on claimed submissions")
+	flag.Parse()
+
+	// Load configuration
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+
+	if cfg.Runner.APIToken == "" {
+		log.Fatal("runner.api_token must be set to reach the server's internal jobs API")
+	}
+
+	judge, err := runner.New(cfg.Runner)
+	if err != nil {
+		log.Fatalf("Error creating runner: %v", err)
+	}
+
+	if _, ok := judge.BuildCacheStats(); ok {
+		expvar.Publish("build_cache", expvar.Func(func() any {
+			stats, _ := judge.BuildCacheStats()
+			return stats
+		}))
+	}
+	if cfg.Runner.MetricsListen != "" {
+		// expvar serves the published counters at /debug/vars
+		go func() {
+			if err := http.ListenAndServe(cfg.Runner.MetricsListen, nil); err != nil {
+				log.Printf("Error serving metrics: %v", err)
+			}
+		}()
+	}
+
+	source := queue.NewClient(cfg.Runner.ServerURL, cfg.Runner.APIToken)
+	worker := runner.NewWorker(*workerID, source, judge,
+		cfg.Runner.MaxConcurrent, cfg.Runner.PollInterval, cfg.Runner.LeaseDuration/3)
+
+	// Stop claiming new submissions on interrupt
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("Runner %s started with %d slots, claiming from %s", *workerID, cfg.Runner.MaxConcurrent, cfg.Runner.ServerURL)
+	worker.Run(ctx)
+	if stats, ok := judge.BuildCacheStats(); ok {
+		log.Printf("Build cache: %d hits, %d misses (%.0f%% hit rate), %d entries using %d MB",
+			stats.Hits, stats.Misses, 100*stats.HitRate, stats.Entries, stats.Bytes>>20)
+	}
+	log.Printf("Runner %s stopped", *workerID)
+}
+
+// defaultWorkerID identifies the runner by host and process.
+func defaultWorkerID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "runner"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
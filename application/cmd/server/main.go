@@ -1,18 +1,35 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
-	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"time"
 
+	"online-judge/internal/auth"
 	"online-judge/internal/config"
 	"online-judge/internal/database"
+	"online-judge/internal/handler"
+	"online-judge/internal/languages"
+	"online-judge/internal/queue"
+	"online-judge/internal/store"
 )
 
+// shutdownTimeout bounds how long in-flight requests may take once a stop signal arrives.
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 	// Parse command line flags
 	configPath := flag.String("config", "config.yaml", "path to config file")
+	listen := flag.String("listen", "", "address to listen on (overrides server.listen from the config)")
+	migrate := flag.Bool("migrate", true, "run pending database migrations before serving")
+	migrationsPath := flag.String("migrations", "migrations", "path to the migrations directory")
 	flag.Parse()
 
 	// Load configuration
@@ -20,25 +37,117 @@ func main() {
 	if err != nil {
 		log.Fatalf("Error loading config: %v", err)
 	}
+	if *listen != "" {
+		cfg.Server.Listen = *listen
+	}
 
 	// Initialize database connection
-	db, err := database.NewDB(cfg.Database)
+	db, err := database.NewDB(database.ConfigFrom(cfg.Database))
 	if err != nil {
 		log.Fatalf("Error connecting to database: %v", err)
 	}
 	defer db.Close()
 
-	// Get the absolute path to the migrations directory
-	migrationsDir, err := filepath.Abs("migrations")
+	if *migrate {
+		// Get the absolute path to the migrations directory
+		migrationsDir, err := filepath.Abs(*migrationsPath)
+		if err != nil {
+			log.Fatalf("Error getting migrations directory path: %v", err)
+		}
+
+		// Run migrations
+		if err := database.RunMigrations(db, migrationsDir); err != nil {
+			log.Fatalf("Error running migrations: %v", err)
+		}
+		log.Println("Database migrations completed successfully")
+	}
+
+	passwords, err := auth.NewPasswordHasher(cfg.Server.BcryptCost)
 	if err != nil {
-		log.Fatalf("Error getting migrations directory path: %v", err)
+		log.Fatalf("Error configuring password hashing: %v", err)
 	}
 
-	// Run migrations
-	if err := database.RunMigrations(db, migrationsDir); err != nil {
-		log.Fatalf("Error running migrations: %v", err)
+	sessionStore := store.NewSessionStore(db)
+	if err := sessionStore.DeleteExpired(context.Background()); err != nil {
+		log.Printf("Warning: Could not prune expired sessions: %v", err)
+	}
+	sessions, err := auth.NewSessionManager(sessionStore, cfg.Server.SecretKey, cfg.Server.SessionTimeout)
+	if err != nil {
+		log.Fatalf("Error configuring sessions: %v", err)
 	}
 
-	// TODO: Initialize and start the HTTP server
-	fmt.Println("Database migrations completed successfully")
+	enabledLanguages, err := languages.NewRegistry(cfg.Runner)
+	if err != nil {
+		log.Fatalf("Error configuring languages: %v", err)
+	}
+
+	if cfg.Runner.APIToken == "" {
+		log.Println("Warning: runner.api_token is not set, the internal jobs API is disabled")
+	} else if cfg.Runner.InternalListen == "" || cfg.Runner.InternalListen == cfg.Server.Listen {
+		log.Fatalf("runner.internal_listen must be set to an address other than server.listen")
+	}
+
+	notifier := queue.NewNotifier()
+	events := queue.NewEvents()
+	h := handler.New(db, handler.Options{
+		Passwords:   passwords,
+		Sessions:    sessions,
+		Jobs:        queue.NewDBQueue(db, cfg.Runner.LeaseDuration),
+		RunnerToken: cfg.Runner.APIToken,
+		Notifier:    notifier,
+		Events:      events,
+		Languages:   enabledLanguages,
+	})
+	server := &http.Server{
+		Addr:              cfg.Server.Listen,
+		Handler:           h.Routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	// The jobs API gets a listener of its own so that it can be kept off
+	// the public network
+	internalServer := &http.Server{
+		Addr:              cfg.Runner.InternalListen,
+		Handler:           h.InternalRoutes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	// Release runners waiting in a claim and end event streams so that
+	// shutdown is not held up
+	internalServer.RegisterOnShutdown(notifier.Close)
+	server.RegisterOnShutdown(events.Close)
+
+	// Requeue submissions abandoned by runners
+	reaperCtx, stopReaper := context.WithCancel(context.Background())
+	defer stopReaper()
+	go queue.NewReaper(db, cfg.Runner.MaxAttempts, cfg.Runner.ReapInterval, notifier, events).Run(reaperCtx)
+
+	// Start the servers
+	go func() {
+		log.Printf("Starting server on %s", cfg.Server.Listen)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Error starting server: %v", err)
+		}
+	}()
+	if cfg.Runner.APIToken != "" {
+		go func() {
+			log.Printf("Serving the internal jobs API on %s", cfg.Runner.InternalListen)
+			if err := internalServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("Error starting internal server: %v", err)
+			}
+		}()
+	}
+
+	// Wait for an interrupt and let in-flight requests finish
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Error shutting down server: %v", err)
+	}
+	if err := internalServer.Shutdown(ctx); err != nil {
+		log.Printf("Error shutting down internal server: %v", err)
+	}
+	log.Println("Server stopped")
 }
@@ -0,0 +1,110 @@
+// Package accounts manages user accounts from outside the web interface, for
+// command line tools such as create-admin.
+package accounts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"unicode/utf8"
+
+	"online-judge/internal/auth"
+	"online-judge/internal/models"
+)
+
+// maxUsernameLength matches the users table's username column.
+const maxUsernameLength = 50
+
+// ErrAlreadyAdmin is returned when promoting a user who already is an admin.
+var ErrAlreadyAdmin = errors.New("user already is an admin")
+
+// UserStore is the persistence needed to create and promote admins.
+// *store.UserStore implements it.
+type UserStore interface {
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	Create(ctx context.Context, user *models.User) error
+	SetRole(ctx context.Context, actorID, userID int, role models.Role) error
+}
+
+// PasswordHasher hashes the passwords of new accounts.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+}
+
+// NewAdmin describes an admin account to create.
+type NewAdmin struct {
+	Username string
+	Email    string
+	FullName string
+	Password string
+}
+
+// Validate checks the account against the limits of the users table and the
+// password rules of the web interface.
+func (a NewAdmin) Validate() error {
+	switch {
+	case a.Username == "":
+		return errors.New("username is required")
+	case utf8.RuneCountInString(a.Username) > maxUsernameLength:
+		return fmt.Errorf("username must be at most %d characters", maxUsernameLength)
+	}
+
+	address, err := mail.ParseAddress(a.Email)
+	if err != nil || address.Address != a.Email {
+		return fmt.Errorf("email %q is not a valid address such as name@example.com", a.Email)
+	}
+
+	switch {
+	case utf8.RuneCountInString(a.Password) < auth.MinPasswordLength:
+		return fmt.Errorf("password must be at least %d characters", auth.MinPasswordLength)
+	case len(a.Password) > auth.MaxPasswordBytes:
+		return fmt.Errorf("password must be at most %d bytes", auth.MaxPasswordBytes)
+	}
+	return nil
+}
+
+// Promote gives the existing user username the admin role. The change is
+// recorded in the audit log without an acting admin. It returns
+// store.ErrNotFound if there is no such user and ErrAlreadyAdmin if the user
+// needs no promotion.
+func Promote(ctx context.Context, users UserStore, username string) (*models.User, error) {
+	user, err := users.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsAdmin() {
+		return user, ErrAlreadyAdmin
+	}
+
+	if err := users.SetRole(ctx, 0, user.ID, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+	user.Role = models.RoleAdmin
+	return user, nil
+}
+
+// CreateAdmin validates admin and inserts it as a new account with the admin
+// role and a hash of its password.
+func CreateAdmin(ctx context.Context, users UserStore, passwords PasswordHasher, admin NewAdmin) (*models.User, error) {
+	if err := admin.Validate(); err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := passwords.Hash(admin.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Username:     admin.Username,
+		Email:        admin.Email,
+		FullName:     admin.FullName,
+		PasswordHash: passwordHash,
+		Role:         models.RoleAdmin,
+	}
+	if err := users.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,118 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"online-judge/internal/models"
+	"online-judge/internal/store"
+)
+
+// fakeUsers is an in-memory UserStore.
+type fakeUsers struct {
+	users   map[string]*models.User
+	changes []string
+}
+
+func (f *fakeUsers) GetByUsername(_ context.Context, username string) (*models.User, error) {
+	user, ok := f.users[username]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (f *fakeUsers) Create(_ context.Context, user *models.User) error {
+	if _, ok := f.users[user.Username]; ok {
+		return store.ErrDuplicate
+	}
+	user.ID = len(f.users) + 1
+	f.users[user.Username] = user
+	return nil
+}
+
+func (f *fakeUsers) SetRole(_ context.Context, actorID, userID int, role models.Role) error {
+	for _, user := range f.users {
+		if user.ID == userID {
+			user.Role = role
+			f.changes = append(f.changes, string(role))
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+type fakeHasher struct{}
+
+func (fakeHasher) Hash(password string) (string, error) {
+	return "hashed:" + password, nil
+}
+
+func newFakeUsers() *fakeUsers {
+	return &fakeUsers{users: map[string]*models.User{
+		"alice": {ID: 1, Username: "alice", Role: models.RoleRegular},
+		"root":  {ID: 2, Username: "root", Role: models.RoleAdmin},
+	}}
+}
+
+func TestPromote(t *testing.T) {
+	users := newFakeUsers()
+
+	user, err := Promote(context.Background(), users, "alice")
+	if err != nil {
+		t.Fatalf("Promote(alice) failed: %v", err)
+	}
+	if !user.IsAdmin() || users.users["alice"].Role != models.RoleAdmin {
+		t.Errorf("alice was not promoted: %+v", user)
+	}
+
+	if _, err := Promote(context.Background(), users, "root"); !errors.Is(err, ErrAlreadyAdmin) {
+		t.Errorf("Promote(root) = %v, want ErrAlreadyAdmin", err)
+	}
+	if _, err := Promote(context.Background(), users, "nobody"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Promote(nobody) = %v, want ErrNotFound", err)
+	}
+	if len(users.changes) != 1 {
+		t.Errorf("recorded %d role changes, want 1", len(users.changes))
+	}
+}
+
+func TestCreateAdmin(t *testing.T) {
+	valid := NewAdmin{Username: "carol", Email: "carol@example.com", FullName: "Carol", Password: "correct horse"}
+
+	tests := []struct {
+		name    string
+		admin   func(NewAdmin) NewAdmin
+		wantErr string
+	}{
+		{name: "valid", admin: func(a NewAdmin) NewAdmin { return a }},
+		{name: "no username", admin: func(a NewAdmin) NewAdmin { a.Username = ""; return a }, wantErr: "username is required"},
+		{name: "long username", admin: func(a NewAdmin) NewAdmin { a.Username = strings.Repeat("c", 51); return a }, wantErr: "at most 50"},
+		{name: "bad email", admin: func(a NewAdmin) NewAdmin { a.Email = "Carol <carol@example.com>"; return a }, wantErr: "not a valid address"},
+		{name: "short password", admin: func(a NewAdmin) NewAdmin { a.Password = "short"; return a }, wantErr: "at least 8"},
+		{name: "long password", admin: func(a NewAdmin) NewAdmin { a.Password = strings.Repeat("p", 73); return a }, wantErr: "at most 72 bytes"},
+		{name: "taken username", admin: func(a NewAdmin) NewAdmin { a.Username = "alice"; return a }, wantErr: store.ErrDuplicate.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newFakeUsers()
+			user, err := CreateAdmin(context.Background(), users, fakeHasher{}, tt.admin(valid))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("CreateAdmin() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateAdmin() failed: %v", err)
+			}
+			if !user.IsAdmin() || user.PasswordHash != "hashed:correct horse" || users.users["carol"] != user {
+				t.Errorf("CreateAdmin() stored %+v", user)
+			}
+		})
+	}
+}
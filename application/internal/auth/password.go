@@ -0,0 +1,87 @@
+// Package auth implements credential hashing and user sessions.
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// MinPasswordLength is the fewest characters a new password may have.
+	MinPasswordLength = 8
+	// MaxPasswordBytes is the most bcrypt can hash; longer passwords are
+	// refused rather than silently truncated.
+	MaxPasswordBytes = 72
+)
+
+// ErrPasswordMismatch is returned when a password does not match its stored hash.
+var ErrPasswordMismatch = errors.New("password does not match")
+
+// PasswordHasher hashes and verifies passwords with bcrypt at a fixed cost.
+type PasswordHasher struct {
+	cost int
+
+	// dummyHash is a hash of a random password at cost, made on first use.
+	dummyOnce sync.Once
+	dummyHash []byte
+}
+
+// NewPasswordHasher creates a PasswordHasher using the given bcrypt cost.
+// A cost of zero selects bcrypt.DefaultCost.
+func NewPasswordHasher(cost int) (*PasswordHasher, error) {
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt cost %d out of range [%d, %d]", cost, bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	return &PasswordHasher{cost: cost}, nil
+}
+
+// Hash returns the bcrypt hash of password.
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", fmt.Errorf("error hashing password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// Verify checks password against hash and returns ErrPasswordMismatch if they
+// differ. A malformed stored hash is treated as a mismatch so that it can never
+// be used to sign in.
+func (h *PasswordHasher) Verify(hash, password string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrPasswordMismatch
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPasswordMismatch, err)
+	}
+	return nil
+}
+
+// VerifyDummy takes as long as Verify with a hash of the hasher's cost, and
+// always fails. Signing in as an unknown user calls it so that the response
+// time does not tell which usernames exist.
+func (h *PasswordHasher) VerifyDummy(password string) error {
+	h.dummyOnce.Do(func() {
+		secret := make([]byte, 16)
+		_, _ = rand.Read(secret)
+		h.dummyHash, _ = bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(secret)), h.cost)
+	})
+	_ = bcrypt.CompareHashAndPassword(h.dummyHash, []byte(password))
+	return ErrPasswordMismatch
+}
+
+// NeedsRehash reports whether hash was produced with a different cost than the
+// hasher's, meaning it should be replaced after the next successful login.
+func (h *PasswordHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.cost
+}
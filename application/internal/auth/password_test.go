@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordHasher(t *testing.T) {
+	hasher, err := NewPasswordHasher(bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("NewPasswordHasher: %v", err)
+	}
+
+	hash, err := hasher.Hash("s3cret")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if hash == "s3cret" {
+		t.Fatal("Hash returned the plaintext password")
+	}
+
+	if err := hasher.Verify(hash, "s3cret"); err != nil {
+		t.Errorf("Verify with correct password: %v", err)
+	}
+	if err := hasher.Verify(hash, "wrong"); !errors.Is(err, ErrPasswordMismatch) {
+		t.Errorf("Verify with wrong password = %v, want ErrPasswordMismatch", err)
+	}
+	if err := hasher.Verify("s3cret", "s3cret"); !errors.Is(err, ErrPasswordMismatch) {
+		t.Errorf("Verify with plaintext stored value = %v, want ErrPasswordMismatch", err)
+	}
+	if err := hasher.VerifyDummy("s3cret"); !errors.Is(err, ErrPasswordMismatch) {
+		t.Errorf("VerifyDummy = %v, want ErrPasswordMismatch", err)
+	}
+	if cost, err := bcrypt.Cost(hasher.dummyHash); err != nil || cost != bcrypt.MinCost {
+		t.Errorf("dummy hash cost = %d (%v), want %d", cost, err, bcrypt.MinCost)
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	oldHasher, err := NewPasswordHasher(bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("NewPasswordHasher: %v", err)
+	}
+	newHasher, err := NewPasswordHasher(bcrypt.MinCost + 1)
+	if err != nil {
+		t.Fatalf("NewPasswordHasher: %v", err)
+	}
+
+	hash, err := oldHasher.Hash("s3cret")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		hasher *PasswordHasher
+		hash   string
+		want   bool
+	}{
+		{"same cost", oldHasher, hash, false},
+		{"changed cost", newHasher, hash, true},
+		{"plaintext value", oldHasher, "admin", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.hasher.NeedsRehash(tt.hash); got != tt.want {
+				t.Errorf("NeedsRehash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPasswordHasherRejectsInvalidCost(t *testing.T) {
+	if _, err := NewPasswordHasher(bcrypt.MaxCost + 1); err == nil {
+		t.Error("expected an error for a cost above bcrypt.MaxCost")
+	}
+}
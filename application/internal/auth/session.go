@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"online-judge/internal/models"
+	"online-judge/internal/store"
+)
+
+// SessionCookieName is the name of the cookie carrying the session token.
+const SessionCookieName = "session"
+
+// ErrNoSession is returned when a request carries no valid, unexpired session.
+var ErrNoSession = errors.New("no valid session")
+
+// SessionStore is the persistence needed by SessionManager. GetByID returns
+// store.ErrNotFound for an unknown session.
+type SessionStore interface {
+	Create(ctx context.Context, session *models.Session) error
+	GetByID(ctx context.Context, id string) (*models.Session, error)
+	Delete(ctx context.Context, id string) error
+	DeleteByUser(ctx context.Context, userID int) error
+	DeleteOthers(ctx context.Context, userID int, keepID string) error
+}
+
+// SessionManager issues, resolves and revokes server-side sessions. The
+// cookie holds a random token signed with the server's secret key; only the
+// token's SHA-256 is stored, so a leaked sessions table cannot be replayed.
+type SessionManager struct {
+	store   SessionStore
+	secret  []byte
+	timeout time.Duration
+	now     func() time.Time
+}
+
+// NewSessionManager creates a SessionManager whose sessions expire after timeout.
+func NewSessionManager(store SessionStore, secretKey string, timeout time.Duration) (*SessionManager, error) {
+	if secretKey == "" {
+		return nil, errors.New("session secret key must not be empty")
+	}
+	if timeout <= 0 {
+		return nil, fmt.Errorf("session timeout must be positive, got %s", timeout)
+	}
+	return &SessionManager{
+		store:   store,
+		secret:  []byte(secretKey),
+		timeout: timeout,
+		now:     time.Now,
+	}, nil
+}
+
+// Start creates a session for userID and sets its cookie on w.
+func (m *SessionManager) Start(ctx context.Context, w http.ResponseWriter, r *http.Request, userID int) error {
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+
+	session := &models.Session{
+		ID:        sessionID(token),
+		UserID:    userID,
+		UserAgent: r.UserAgent(),
+		ExpiresAt: m.now().Add(m.timeout),
+	}
+	if err := m.store.Create(ctx, session); err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    token + "." + m.sign(token),
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		MaxAge:   int(m.timeout.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// Resolve returns the session of the request, or ErrNoSession if the cookie
+// is missing, forged, revoked or expired. Other errors mean the session could
+// not be looked up.
+func (m *SessionManager) Resolve(ctx context.Context, r *http.Request) (*models.Session, error) {
+	token, ok := m.tokenFromRequest(r)
+	if !ok {
+		return nil, ErrNoSession
+	}
+
+	session, err := m.store.GetByID(ctx, sessionID(token))
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrNoSession
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error resolving session: %w", err)
+	}
+
+	if !m.now().Before(session.ExpiresAt) {
+		if err := m.store.Delete(ctx, session.ID); err != nil {
+			return nil, err
+		}
+		return nil, ErrNoSession
+	}
+	return session, nil
+}
+
+// End revokes the session of the request, if any, and clears its cookie.
+func (m *SessionManager) End(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	clearCookie(w)
+
+	token, ok := m.tokenFromRequest(r)
+	if !ok {
+		return nil
+	}
+	return m.store.Delete(ctx, sessionID(token))
+}
+
+// EndAll revokes every session of userID and clears the cookie of the request.
+func (m *SessionManager) EndAll(ctx context.Context, w http.ResponseWriter, userID int) error {
+	clearCookie(w)
+	return m.store.DeleteByUser(ctx, userID)
+}
+
+// EndOthers revokes every session of userID except the one of the request,
+// signing the user out on all other devices.
+func (m *SessionManager) EndOthers(ctx context.Context, r *http.Request, userID int) error {
+	token, ok := m.tokenFromRequest(r)
+	if !ok {
+		return m.store.DeleteByUser(ctx, userID)
+	}
+	return m.store.DeleteOthers(ctx, userID, sessionID(token))
+}
+
+// tokenFromRequest extracts the session token and checks its signature.
+func (m *SessionManager) tokenFromRequest(r *http.Request) (string, bool) {
+	cookie, err := r.Cookie(SessionCookieName)
+	if err != nil {
+		return "", false
+	}
+
+	token, signature, found := strings.Cut(cookie.Value, ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(m.sign(token))) {
+		return "", false
+	}
+	return token, true
+}
+
+func (m *SessionManager) sign(token string) string {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("error generating session token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func sessionID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func clearCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"online-judge/internal/models"
+	"online-judge/internal/store"
+)
+
+// memorySessionStore is an in-memory SessionStore for tests.
+type memorySessionStore struct {
+	sessions map[string]models.Session
+}
+
+func newMemorySessionStore() *memorySessionStore {
+	return &memorySessionStore{sessions: make(map[string]models.Session)}
+}
+
+func (s *memorySessionStore) Create(_ context.Context, session *models.Session) error {
+	s.sessions[session.ID] = *session
+	return nil
+}
+
+func (s *memorySessionStore) GetByID(_ context.Context, id string) (*models.Session, error) {
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &session, nil
+}
+
+func (s *memorySessionStore) Delete(_ context.Context, id string) error {
+	delete(s.sessions, id)
+	return nil
+}
+
+func (s *memorySessionStore) DeleteByUser(_ context.Context, userID int) error {
+	for id, session := range s.sessions {
+		if session.UserID == userID {
+			delete(s.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (s *memorySessionStore) DeleteOthers(_ context.Context, userID int, keepID string) error {
+	for id, session := range s.sessions {
+		if session.UserID == userID && id != keepID {
+			delete(s.sessions, id)
+		}
+	}
+	return nil
+}
+
+// startSession signs userID in and returns a request carrying the resulting cookie.
+func startSession(t *testing.T, m *SessionManager, userID int) *http.Request {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	if err := m.Start(context.Background(), rec, httptest.NewRequest("POST", "/login", nil), userID); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Start set %d cookies, want 1", len(cookies))
+	}
+	if !cookies[0].HttpOnly || cookies[0].SameSite != http.SameSiteLaxMode {
+		t.Errorf("session cookie is not HttpOnly/SameSite=Lax: %+v", cookies[0])
+	}
+
+	req := httptest.NewRequest("GET", "/dashboard", nil)
+	req.AddCookie(cookies[0])
+	return req
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	ctx := context.Background()
+	m, err := NewSessionManager(newMemorySessionStore(), "test-secret", time.Hour)
+	if err != nil {
+		t.Fatalf("NewSessionManager: %v", err)
+	}
+
+	req := startSession(t, m, 42)
+	session, err := m.Resolve(ctx, req)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if session.UserID != 42 {
+		t.Errorf("session.UserID = %d, want 42", session.UserID)
+	}
+
+	if err := m.End(ctx, httptest.NewRecorder(), req); err != nil {
+		t.Fatalf("End: %v", err)
+	}
+	if _, err := m.Resolve(ctx, req); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Resolve after End = %v, want ErrNoSession", err)
+	}
+}
+
+func TestSessionRejectsTamperedCookie(t *testing.T) {
+	m, err := NewSessionManager(newMemorySessionStore(), "test-secret", time.Hour)
+	if err != nil {
+		t.Fatalf("NewSessionManager: %v", err)
+	}
+	startSession(t, m, 1)
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"raw username", "admin"},
+		{"unsigned token", "abc"},
+		{"wrong signature", "abc.def"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: tt.value})
+			if _, err := m.Resolve(context.Background(), req); !errors.Is(err, ErrNoSession) {
+				t.Errorf("Resolve = %v, want ErrNoSession", err)
+			}
+		})
+	}
+}
+
+// failingSessionStore is a SessionStore whose database is unreachable.
+type failingSessionStore struct {
+	*memorySessionStore
+}
+
+var errDatabaseDown = errors.New("connection refused")
+
+func (s failingSessionStore) GetByID(context.Context, string) (*models.Session, error) {
+	return nil, errDatabaseDown
+}
+
+func TestSessionReportsStoreFailure(t *testing.T) {
+	m, err := NewSessionManager(failingSessionStore{newMemorySessionStore()}, "test-secret", time.Hour)
+	if err != nil {
+		t.Fatalf("NewSessionManager: %v", err)
+	}
+
+	req := startSession(t, m, 1)
+	_, err = m.Resolve(context.Background(), req)
+	if errors.Is(err, ErrNoSession) || !errors.Is(err, errDatabaseDown) {
+		t.Errorf("Resolve with the store down = %v, want the store's error", err)
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	store := newMemorySessionStore()
+	m, err := NewSessionManager(store, "test-secret", time.Minute)
+	if err != nil {
+		t.Fatalf("NewSessionManager: %v", err)
+	}
+
+	req := startSession(t, m, 1)
+	m.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+
+	if _, err := m.Resolve(context.Background(), req); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Resolve of expired session = %v, want ErrNoSession", err)
+	}
+	if len(store.sessions) != 0 {
+		t.Errorf("expired session was not removed from the store")
+	}
+}
+
+func TestEndAllRevokesEveryDevice(t *testing.T) {
+	ctx := context.Background()
+	m, err := NewSessionManager(newMemorySessionStore(), "test-secret", time.Hour)
+	if err != nil {
+		t.Fatalf("NewSessionManager: %v", err)
+	}
+
+	laptop := startSession(t, m, 7)
+	phone := startSession(t, m, 7)
+	other := startSession(t, m, 8)
+
+	if err := m.EndAll(ctx, httptest.NewRecorder(), 7); err != nil {
+		t.Fatalf("EndAll: %v", err)
+	}
+
+	for _, req := range []*http.Request{laptop, phone} {
+		if _, err := m.Resolve(ctx, req); !errors.Is(err, ErrNoSession) {
+			t.Errorf("Resolve after EndAll = %v, want ErrNoSession", err)
+		}
+	}
+	if _, err := m.Resolve(ctx, other); err != nil {
+		t.Errorf("EndAll revoked another user's session: %v", err)
+	}
+}
+
+func TestEndOthersKeepsCurrentDevice(t *testing.T) {
+	ctx := context.Background()
+	m, err := NewSessionManager(newMemorySessionStore(), "test-secret", time.Hour)
+	if err != nil {
+		t.Fatalf("NewSessionManager: %v", err)
+	}
+
+	laptop := startSession(t, m, 7)
+	phone := startSession(t, m, 7)
+	other := startSession(t, m, 8)
+
+	if err := m.EndOthers(ctx, laptop, 7); err != nil {
+		t.Fatalf("EndOthers: %v", err)
+	}
+
+	if _, err := m.Resolve(ctx, laptop); err != nil {
+		t.Errorf("EndOthers revoked the current session: %v", err)
+	}
+	if _, err := m.Resolve(ctx, phone); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Resolve of other device after EndOthers = %v, want ErrNoSession", err)
+	}
+	if _, err := m.Resolve(ctx, other); err != nil {
+		t.Errorf("EndOthers revoked another user's session: %v", err)
+	}
+}
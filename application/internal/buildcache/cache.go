@@ -0,0 +1,334 @@
+// Package buildcache keeps the output of compilations on disk so that
+// identical programs are only built once. Entries are content-addressed:
+// their key hashes everything the build depends on, so they never need to be
+// invalidated, only evicted when the cache outgrows its size.
+package buildcache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stagingPrefix names the directories entries are assembled in before they
+// are added to the cache.
+const stagingPrefix = "staging-"
+
+// Key returns the cache key of a build of source in language with the given
+// toolchain, which should identify the compiler version and its options.
+func Key(language, toolchain, source string) string {
+	sourceHash := sha256.Sum256([]byte(source))
+	h := sha256.New()
+	for _, part := range []string{language, toolchain, hex.EncodeToString(sourceHash[:])} {
+		io.WriteString(h, part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Stats counts the lookups of a Cache since it was opened.
+type Stats struct {
+	Hits      int64   `json:"hits"`
+	Misses    int64   `json:"misses"`
+	HitRate   float64 `json:"hit_rate"` // hits per lookup, 0 before the first one
+	Evictions int64   `json:"evictions"`
+	Entries   int     `json:"entries"`
+	Bytes     int64   `json:"bytes"`
+}
+
+// Cache is a size-bounded store of build directories, evicting the least
+// recently used entries first. It is safe for concurrent use, but the
+// directory must not be shared with another Cache.
+type Cache struct {
+	dir      string
+	maxBytes int64
+
+	mu        sync.Mutex
+	order     *list.List // of *entry, most recently used first
+	entries   map[string]*list.Element
+	bytes     int64
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+type entry struct {
+	key   string
+	bytes int64
+	// readers counts the Gets copying the entry. An entry evicted while
+	// it is read leaves its files to the last of them to remove.
+	readers int
+	evicted bool
+}
+
+// Open opens the cache in dir, creating the directory if needed, and indexes
+// the entries left by earlier runs in the order they were last used. Since
+// cached builds are run as they are, dir must be private: owned by this
+// process's user, with mode 0700.
+func Open(dir string, maxBytes int64) (*Cache, error) {
+	if maxBytes <= 0 {
+		return nil, errors.New("build cache size must be positive")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("error creating build cache: %w", err)
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error opening build cache: %w", err)
+	}
+	if !info.IsDir() || info.Mode().Perm() != 0o700 || !ownedBySelf(info) {
+		return nil, fmt.Errorf("build cache %s must be a directory owned by the runner's user with mode 0700", dir)
+	}
+	c := &Cache{dir: dir, maxBytes: maxBytes, order: list.New(), entries: make(map[string]*list.Element)}
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading build cache: %w", err)
+	}
+	type found struct {
+		entry
+		used time.Time
+	}
+	var existing []found
+	for _, dirEntry := range dirEntries {
+		path := filepath.Join(dir, dirEntry.Name())
+		if strings.HasPrefix(dirEntry.Name(), stagingPrefix) {
+			// Left over by an interrupted Put
+			os.RemoveAll(path)
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil || !dirEntry.IsDir() || !isKey(dirEntry.Name()) {
+			continue
+		}
+		size, err := treeSize(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading build cache: %w", err)
+		}
+		existing = append(existing, found{entry{key: dirEntry.Name(), bytes: size}, info.ModTime()})
+	}
+	sort.Slice(existing, func(i, j int) bool { return existing[i].used.After(existing[j].used) })
+	for _, e := range existing {
+		e := e.entry
+		c.entries[e.key] = c.order.PushBack(&e)
+		c.bytes += e.bytes
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evict()
+	return c, nil
+}
+
+// Get copies the files of the entry with the given key into dir and reports
+// whether there was one.
+func (c *Cache) Get(key, dir string) (bool, error) {
+	c.mu.Lock()
+	element, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		c.mu.Unlock()
+		return false, nil
+	}
+	e := element.Value.(*entry)
+	e.readers++
+	c.order.MoveToFront(element)
+	c.mu.Unlock()
+
+	// Being read keeps the entry's files in place until the copy is done.
+	path := filepath.Join(c.dir, key)
+	err := copyTree(path, dir, nil)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.release(e)
+	if err != nil {
+		c.misses++
+		return false, fmt.Errorf("error restoring cached build: %w", err)
+	}
+	c.hits++
+	if !e.evicted {
+		// The modification time orders the entries when the cache is reopened.
+		now := time.Now()
+		os.Chtimes(path, now, now)
+	}
+	return true, nil
+}
+
+// release ends a read of e, removing its files if it was evicted meanwhile
+// and this was the last read. c.mu must be held.
+func (c *Cache) release(e *entry) {
+	e.readers--
+	if e.evicted && e.readers == 0 {
+		os.RemoveAll(filepath.Join(c.dir, e.key))
+	}
+}
+
+// Put stores the files in dir, except the top-level ones named in skip, as
+// the entry with the given key. Entries larger than the whole cache are not
+// stored.
+func (c *Cache) Put(key, dir string, skip ...string) error {
+	if !isKey(key) {
+		return fmt.Errorf("invalid build cache key %q", key)
+	}
+	staging, err := os.MkdirTemp(c.dir, stagingPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("error storing build: %w", err)
+	}
+	defer os.RemoveAll(staging)
+	if err := copyTree(dir, staging, skip); err != nil {
+		return fmt.Errorf("error storing build: %w", err)
+	}
+	size, err := treeSize(staging)
+	if err != nil {
+		return fmt.Errorf("error storing build: %w", err)
+	}
+	if size > c.maxBytes {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		// Built concurrently by another judge
+		c.order.MoveToFront(element)
+		return nil
+	}
+	target := filepath.Join(c.dir, key)
+	if _, err := os.Lstat(target); err == nil {
+		// Evicted, but still being read
+		return nil
+	}
+	if err := os.Rename(staging, target); err != nil {
+		return fmt.Errorf("error storing build: %w", err)
+	}
+	c.entries[key] = c.order.PushFront(&entry{key: key, bytes: size})
+	c.bytes += size
+	c.evict()
+	return nil
+}
+
+// Stats returns the cache's counters.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := Stats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Entries:   len(c.entries),
+		Bytes:     c.bytes,
+	}
+	if lookups := c.hits + c.misses; lookups > 0 {
+		stats.HitRate = float64(c.hits) / float64(lookups)
+	}
+	return stats
+}
+
+// evict removes the least recently used entries until the cache fits its
+// size. c.mu must be held.
+func (c *Cache) evict() {
+	for c.bytes > c.maxBytes {
+		element := c.order.Back()
+		e := element.Value.(*entry)
+		c.order.Remove(element)
+		delete(c.entries, e.key)
+		c.bytes -= e.bytes
+		c.evictions++
+		if e.readers > 0 {
+			e.evicted = true
+			continue
+		}
+		os.RemoveAll(filepath.Join(c.dir, e.key))
+	}
+}
+
+// isKey reports whether name is a key returned by Key.
+func isKey(name string) bool {
+	return len(name) == 2*sha256.Size && strings.Trim(name, "0123456789abcdef") == ""
+}
+
+// treeSize returns the total size of the regular files under dir.
+func treeSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// copyTree copies the directories and regular files under src into dst,
+// keeping their permissions and leaving out the top-level names in skip.
+func copyTree(src, dst string, skip []string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		for _, name := range skip {
+			if rel == name {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		}
+		return nil
+	})
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
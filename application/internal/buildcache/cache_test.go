@@ -0,0 +1,219 @@
+package buildcache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// buildDir returns a directory holding a source file and an executable of
+// size bytes, as left by a compiler.
+func buildDir(t *testing.T, size int) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.c"), []byte("int main(void) { return 0; }"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main"), []byte(strings.Repeat("x", size)), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// cacheDir returns a path for a cache directory that Open creates.
+func cacheDir(t *testing.T) string {
+	t.Helper()
+	return filepath.Join(t.TempDir(), "cache")
+}
+
+func TestKey(t *testing.T) {
+	base := Key("c", "gcc 12", "int main(void) {}")
+	if !isKey(base) {
+		t.Fatalf("Key returned %q", base)
+	}
+	if Key("c", "gcc 12", "int main(void) {}") != base {
+		t.Error("Key is not deterministic")
+	}
+
+	tests := []struct {
+		name                        string
+		language, toolchain, source string
+	}{
+		{"other language", "cpp", "gcc 12", "int main(void) {}"},
+		{"other toolchain", "c", "gcc 13", "int main(void) {}"},
+		{"other source", "c", "gcc 12", "int main(void) { }"},
+		{"fields are not concatenated", "c", "gcc 1", "2int main(void) {}"},
+	}
+	for _, tt := range tests {
+		if Key(tt.language, tt.toolchain, tt.source) == base {
+			t.Errorf("%s: same key as the base build", tt.name)
+		}
+	}
+}
+
+func TestGetRestoresStoredBuild(t *testing.T) {
+	cache, err := Open(cacheDir(t), 1<<20)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	key := Key("c", "gcc", "source")
+
+	dst := t.TempDir()
+	if hit, err := cache.Get(key, dst); hit || err != nil {
+		t.Fatalf("Get before Put = %v, %v", hit, err)
+	}
+	if err := cache.Put(key, buildDir(t, 100), "main.c"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if hit, err := cache.Get(key, dst); !hit || err != nil {
+		t.Fatalf("Get after Put = %v, %v", hit, err)
+	}
+
+	info, err := os.Stat(filepath.Join(dst, "main"))
+	if err != nil {
+		t.Fatalf("restored binary: %v", err)
+	}
+	if info.Size() != 100 || info.Mode().Perm()&0o100 == 0 {
+		t.Errorf("restored binary has size %d and mode %v", info.Size(), info.Mode())
+	}
+	if _, err := os.Stat(filepath.Join(dst, "main.c")); !os.IsNotExist(err) {
+		t.Errorf("skipped source file was cached: %v", err)
+	}
+
+	want := Stats{Hits: 1, Misses: 1, HitRate: 0.5, Entries: 1, Bytes: 100}
+	if got := cache.Stats(); got != want {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+}
+
+func TestPutEvictsLeastRecentlyUsed(t *testing.T) {
+	cache, err := Open(cacheDir(t), 250)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	first, second, third := Key("c", "gcc", "1"), Key("c", "gcc", "2"), Key("c", "gcc", "3")
+
+	for _, key := range []string{first, second} {
+		if err := cache.Put(key, buildDir(t, 100), "main.c"); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	// Using the first build makes the second the least recently used.
+	if hit, _ := cache.Get(first, t.TempDir()); !hit {
+		t.Fatal("first build missing")
+	}
+	if err := cache.Put(third, buildDir(t, 100), "main.c"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	for key, want := range map[string]bool{first: true, second: false, third: true} {
+		if hit, _ := cache.Get(key, t.TempDir()); hit != want {
+			t.Errorf("Get(%s) = %v, want %v", key[:8], hit, want)
+		}
+	}
+	if stats := cache.Stats(); stats.Evictions != 1 || stats.Bytes != 200 {
+		t.Errorf("Stats = %+v, want one eviction and 200 bytes", stats)
+	}
+}
+
+func TestPutSkipsBuildsLargerThanTheCache(t *testing.T) {
+	cache, err := Open(cacheDir(t), 50)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	key := Key("c", "gcc", "huge")
+	if err := cache.Put(key, buildDir(t, 100), "main.c"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if hit, _ := cache.Get(key, t.TempDir()); hit {
+		t.Error("build larger than the cache was stored")
+	}
+}
+
+func TestOpenKeepsEntriesInUseOrder(t *testing.T) {
+	dir := cacheDir(t)
+	cache, err := Open(dir, 1<<20)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	older, newer := Key("c", "gcc", "older"), Key("c", "gcc", "newer")
+	for _, key := range []string{older, newer} {
+		if err := cache.Put(key, buildDir(t, 100), "main.c"); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, older), past, past); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, stagingPrefix+"interrupted"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopening with room for one entry keeps the most recently used one.
+	reopened, err := Open(dir, 150)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if hit, _ := reopened.Get(newer, t.TempDir()); !hit {
+		t.Error("most recently used entry was not kept")
+	}
+	if hit, _ := reopened.Get(older, t.TempDir()); hit {
+		t.Error("least recently used entry was not evicted")
+	}
+	if _, err := os.Stat(filepath.Join(dir, stagingPrefix+"interrupted")); !os.IsNotExist(err) {
+		t.Errorf("staging directory was left behind: %v", err)
+	}
+}
+
+func TestOpenRefusesSharedDirectory(t *testing.T) {
+	dir := cacheDir(t)
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(dir, 1<<20); err == nil {
+		t.Error("Open accepted a directory others can read")
+	}
+}
+
+func TestEvictionWaitsForReaders(t *testing.T) {
+	dir := cacheDir(t)
+	cache, err := Open(dir, 150)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	read, other := Key("c", "gcc", "read"), Key("c", "gcc", "other")
+	if err := cache.Put(read, buildDir(t, 100), "main.c"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	// As if a Get were copying the entry when another build evicts it
+	cache.mu.Lock()
+	e := cache.entries[read].Value.(*entry)
+	e.readers++
+	cache.mu.Unlock()
+	if err := cache.Put(other, buildDir(t, 100), "main.c"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, read)); err != nil {
+		t.Fatalf("entry being read was removed: %v", err)
+	}
+	if err := cache.Put(read, buildDir(t, 100), "main.c"); err != nil {
+		t.Errorf("Put of an entry still being read: %v", err)
+	}
+
+	cache.mu.Lock()
+	cache.release(e)
+	cache.mu.Unlock()
+	if _, err := os.Stat(filepath.Join(dir, read)); !os.IsNotExist(err) {
+		t.Errorf("evicted entry was left behind by its last reader: %v", err)
+	}
+	if hit, _ := cache.Get(read, t.TempDir()); hit {
+		t.Error("evicted entry was still found")
+	}
+}
@@ -0,0 +1,16 @@
+//go:build unix
+
+package buildcache
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// ownedBySelf reports whether the file described by info belongs to the
+// user this process runs as.
+func ownedBySelf(info fs.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(stat.Uid) == os.Geteuid()
+}
@@ -2,9 +2,13 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/spf13/viper"
+
+	"online-judge/internal/models"
 )
 
 type Config struct {
@@ -30,13 +34,51 @@ type ServerConfig struct {
 	Listen         string        `mapstructure:"listen"`
 	SecretKey      string        `mapstructure:"secret_key"`
 	SessionTimeout time.Duration `mapstructure:"session_timeout"`
+	BcryptCost     int           `mapstructure:"bcrypt_cost"`
 }
 
 type RunnerConfig struct {
-	MaxConcurrent int    `mapstructure:"max_concurrent"`
-	Timeout       string `mapstructure:"timeout"`
-	MemoryLimitMB int    `mapstructure:"memory_limit_mb"`
-	CPULimit      int    `mapstructure:"cpu_limit"`
+	MaxConcurrent int           `mapstructure:"max_concurrent"`
+	Timeout       time.Duration `mapstructure:"timeout"`
+	MemoryLimitMB int           `mapstructure:"memory_limit_mb"`
+	CPULimit      int           `mapstructure:"cpu_limit"`
+	LeaseDuration time.Duration `mapstructure:"lease_duration"`
+	PollInterval  time.Duration `mapstructure:"poll_interval"`
+	MaxAttempts   int           `mapstructure:"max_attempts"`
+	ReapInterval  time.Duration `mapstructure:"reap_interval"`
+	// InternalListen is the address the server serves the jobs API for
+	// runners on, apart from the public pages; ServerURL must reach it.
+	InternalListen string `mapstructure:"internal_listen"`
+	ServerURL      string `mapstructure:"server_url"`
+	APIToken       string `mapstructure:"api_token"`
+	// EnabledLanguages are the IDs of the languages submissions may use.
+	EnabledLanguages []string `mapstructure:"enabled_languages"`
+	// Languages override built-in languages or define new ones, by ID.
+	Languages map[string]LanguageConfig `mapstructure:"languages"`
+	// ToolchainDir holds a home directory for the compiler of each
+	// language, where compilers such as Go's keep their caches.
+	ToolchainDir string `mapstructure:"toolchain_dir"`
+	// BuildCacheDir holds compiled submissions for reuse, up to
+	// BuildCacheMB; a size of 0 disables the cache. It must be private to
+	// the runner's user.
+	BuildCacheDir string `mapstructure:"build_cache_dir"`
+	BuildCacheMB  int    `mapstructure:"build_cache_mb"`
+	// MetricsListen, if set, is the address the runner serves its metrics
+	// on at /debug/vars.
+	MetricsListen string `mapstructure:"metrics_listen"`
+}
+
+// LanguageConfig describes a language; unset fields of a built-in language
+// keep their defaults.
+type LanguageConfig struct {
+	Name             string   `mapstructure:"name"`
+	SourceFile       string   `mapstructure:"source_file"`
+	Compile          []string `mapstructure:"compile"`
+	CompileEnv       []string `mapstructure:"compile_env"`
+	Version          []string `mapstructure:"version"`
+	Run              []string `mapstructure:"run"`
+	TimeMultiplier   float64  `mapstructure:"time_multiplier"`
+	MemoryOverheadMB int      `mapstructure:"memory_overhead_mb"`
 }
 
 func LoadConfig(configPath string) (*Config, error) {
@@ -54,11 +96,22 @@ func LoadConfig(configPath string) (*Config, error) {
 
 	viper.SetDefault("server.listen", ":8080")
 	viper.SetDefault("server.session_timeout", "24h")
+	viper.SetDefault("server.bcrypt_cost", 10)
 
 	viper.SetDefault("runner.max_concurrent", 5)
 	viper.SetDefault("runner.timeout", "30s")
-	viper.SetDefault("runner.memory_limit_mb", 256)
+	viper.SetDefault("runner.memory_limit_mb", models.MaxMemoryLimitMB)
 	viper.SetDefault("runner.cpu_limit", 1)
+	viper.SetDefault("runner.lease_duration", "2m")
+	viper.SetDefault("runner.poll_interval", "1s")
+	viper.SetDefault("runner.max_attempts", 3)
+	viper.SetDefault("runner.reap_interval", "30s")
+	viper.SetDefault("runner.internal_listen", "127.0.0.1:8081")
+	viper.SetDefault("runner.server_url", "http://localhost:8081")
+	viper.SetDefault("runner.enabled_languages", []string{"go", "c", "cpp", "python3", "java", "rust"})
+	viper.SetDefault("runner.toolchain_dir", filepath.Join(defaultCacheDir(), "toolchains"))
+	viper.SetDefault("runner.build_cache_dir", filepath.Join(defaultCacheDir(), "builds"))
+	viper.SetDefault("runner.build_cache_mb", 512)
 
 	// Read environment variables
 	viper.AutomaticEnv()
@@ -82,6 +135,43 @@ func LoadConfig(configPath string) (*Config, error) {
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
+	if err := config.Runner.validate(); err != nil {
+		return nil, fmt.Errorf("invalid runner config: %w", err)
+	}
 
 	return &config, nil
 }
+
+// defaultCacheDir is where the runner keeps its files by default: in the
+// cache directory of the user it runs as, such as ~/.cache, which other
+// users cannot write to.
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "online-judge")
+}
+
+// minLeaseDuration leaves runners, which renew their leases every third of
+// the lease duration, time to do so.
+const minLeaseDuration = 3 * time.Second
+
+// validate rejects runner settings that would crash the server or runner, or
+// silently judge questions by other limits than they set.
+func (c *RunnerConfig) validate() error {
+	switch {
+	case c.MaxConcurrent < 1:
+		return fmt.Errorf("max_concurrent must be at least 1, got %d", c.MaxConcurrent)
+	case c.LeaseDuration < minLeaseDuration:
+		return fmt.Errorf("lease_duration must be at least %s, got %s", minLeaseDuration, c.LeaseDuration)
+	case c.PollInterval <= 0:
+		return fmt.Errorf("poll_interval must be positive, got %s", c.PollInterval)
+	case c.ReapInterval <= 0:
+		return fmt.Errorf("reap_interval must be positive, got %s", c.ReapInterval)
+	case c.MemoryLimitMB < 0 || (c.MemoryLimitMB > 0 && c.MemoryLimitMB < models.MaxMemoryLimitMB):
+		return fmt.Errorf("memory_limit_mb must be 0 (no cap) or at least %d, the highest question memory limit, got %d",
+			models.MaxMemoryLimitMB, c.MemoryLimitMB)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunnerConfigValidate(t *testing.T) {
+	valid := func() RunnerConfig {
+		return RunnerConfig{
+			MaxConcurrent: 5,
+			MemoryLimitMB: 1024,
+			LeaseDuration: 2 * time.Minute,
+			PollInterval:  time.Second,
+			ReapInterval:  30 * time.Second,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*RunnerConfig)
+		wantErr string
+	}{
+		{"defaults", func(c *RunnerConfig) {}, ""},
+		{"no memory cap", func(c *RunnerConfig) { c.MemoryLimitMB = 0 }, ""},
+		{"memory cap above question limits", func(c *RunnerConfig) { c.MemoryLimitMB = 2048 }, ""},
+		{"memory cap below question limits", func(c *RunnerConfig) { c.MemoryLimitMB = 256 }, "memory_limit_mb"},
+		{"negative memory cap", func(c *RunnerConfig) { c.MemoryLimitMB = -1 }, "memory_limit_mb"},
+		{"no reap interval", func(c *RunnerConfig) { c.ReapInterval = 0 }, "reap_interval"},
+		{"no poll interval", func(c *RunnerConfig) { c.PollInterval = 0 }, "poll_interval"},
+		{"no lease", func(c *RunnerConfig) { c.LeaseDuration = 0 }, "lease_duration"},
+		{"lease too short to renew", func(c *RunnerConfig) { c.LeaseDuration = 2 * time.Nanosecond }, "lease_duration"},
+		{"no slots", func(c *RunnerConfig) { c.MaxConcurrent = 0 }, "max_concurrent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid()
+			tt.modify(&c)
+			err := c.validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validate() = %v, want an error about %s", err, tt.wantErr)
+			}
+		})
+	}
+}
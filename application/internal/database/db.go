@@ -6,6 +6,8 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+
+	"online-judge/internal/config"
 )
 
 type Config struct {
@@ -40,3 +42,19 @@ func NewDB(cfg Config) (*sqlx.DB, error) {
 
 	return db, nil
 }
+
+// ConfigFrom builds a connection Config from the application's database settings.
+func ConfigFrom(cfg config.DatabaseConfig) Config {
+	return Config{
+		Host:            cfg.Host,
+		Port:            cfg.Port,
+		User:            cfg.User,
+		Password:        cfg.Password,
+		DBName:          cfg.DBName,
+		SSLMode:         cfg.SSLMode,
+		MaxOpenConns:    cfg.MaxOpenConns,
+		MaxIdleConns:    cfg.MaxIdleConns,
+		ConnMaxLifetime: cfg.ConnMaxLifetime,
+		ConnectTimeout:  cfg.ConnectTimeout,
+	}
+}
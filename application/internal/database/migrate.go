@@ -38,7 +38,7 @@ func RunMigrations(db *sqlx.DB, migrationsDir string) error {
 		if strings.HasSuffix(file.Name(), ".up.sql") {
 			version := extractVersion(file.Name())
 			upPath := filepath.Join(migrationsDir, file.Name())
-			downPath := filepath.Join(migrationsDir, fmt.Sprintf("%d_init_schema.down.sql", version))
+			downPath := filepath.Join(migrationsDir, strings.TrimSuffix(file.Name(), ".up.sql")+".down.sql")
 
 			upSQL, err := ioutil.ReadFile(upPath)
 			if err != nil {
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+
+	"online-judge/internal/middleware"
+	"online-judge/internal/models"
+	"online-judge/internal/store"
+)
+
+// failuresHandler lists the submissions that could not be judged after repeated runner failures
+func (h *Handler) failuresHandler(w http.ResponseWriter, r *http.Request) {
+	user := middleware.UserFromContext(r.Context())
+
+	failures, err := h.submissions.ListByResult(r.Context(), models.ResultSystemError)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := PageData{
+		Title:       "Judging Failures",
+		User:        user,
+		Submissions: failures,
+	}
+
+	tmpl, err := template.ParseFiles(
+		"templates/base.html",
+		"templates/admin/failures.html",
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// reviewAction is an admin decision on a question and the status change it makes.
+type reviewAction struct {
+	from, to     models.QuestionStatus
+	needsComment bool
+}
+
+// reviewActions are the decisions that can be posted from the review page.
+// Rejections must explain what to change; the owner sees the comment.
+var reviewActions = map[string]reviewAction{
+	"approve":   {from: models.QuestionReview, to: models.QuestionPublished},
+	"reject":    {from: models.QuestionReview, to: models.QuestionDraft, needsComment: true},
+	"unpublish": {from: models.QuestionPublished, to: models.QuestionDraft},
+}
+
+// maxReviewCommentLength bounds the comment an admin leaves on a question.
+const maxReviewCommentLength = 2000
+
+// adminQuestionsHandler lists all questions with the review actions available for each
+func (h *Handler) adminQuestionsHandler(w http.ResponseWriter, r *http.Request) {
+	user := middleware.UserFromContext(r.Context())
+
+	questionsList, err := h.questions.List(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := PageData{
+		Title:     "Question Review",
+		User:      user,
+		Error:     r.URL.Query().Get("error"),
+		Questions: questionsList,
+	}
+
+	tmpl, err := template.ParseFiles(
+		"templates/base.html",
+		"templates/admin/questions.html",
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// reviewQuestionHandler approves, rejects or unpublishes a question
+func (h *Handler) reviewQuestionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	action, ok := reviewActions[r.FormValue("action")]
+	if !ok {
+		http.Error(w, "Unknown review action", http.StatusBadRequest)
+		return
+	}
+	comment := normalizeNewlines(strings.TrimSpace(r.FormValue("comment")))
+	if action.needsComment && comment == "" {
+		redirectWithError(w, r, "/admin/questions", "A comment is required to reject a question")
+		return
+	}
+	if utf8.RuneCountInString(comment) > maxReviewCommentLength {
+		redirectWithError(w, r, "/admin/questions", fmt.Sprintf("Comments must be at most %d characters", maxReviewCommentLength))
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	err := h.questions.SetStatus(r.Context(), id, action.from, action.to, comment)
+	if errors.Is(err, store.ErrConflict) {
+		redirectWithError(w, r, "/admin/questions", fmt.Sprintf("Question %d is no longer %s", id, action.from))
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/admin/questions", http.StatusSeeOther)
+}
+
+// redirectWithError sends the browser back to a page, asking it to show message.
+func redirectWithError(w http.ResponseWriter, r *http.Request, path, message string) {
+	http.Redirect(w, r, path+"?error="+url.QueryEscape(message), http.StatusSeeOther)
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"online-judge/internal/middleware"
+	"online-judge/internal/models"
+	"online-judge/internal/store"
+)
+
+const (
+	// usersPerPage is the page size of the admin user list.
+	usersPerPage = 25
+	// auditEntriesShown is how many of the latest account changes the user
+	// list shows.
+	auditEntriesShown = 20
+)
+
+// parseUserQuery reads the admin user list's query parameters:
+//
+//	q       substring of the username, email or full name
+//	role    regular or admin
+//	status  active or disabled
+//	after   ID of the last user of the previous page, from next links
+//	before  ID of the first user of the next page, from previous links
+func parseUserQuery(values url.Values) (store.UserQuery, error) {
+	query := store.UserQuery{
+		Search:   strings.TrimSpace(values.Get("q")),
+		Role:     models.Role(values.Get("role")),
+		Disabled: values.Get("status"),
+		Limit:    usersPerPage,
+	}
+
+	switch query.Role {
+	case "", models.RoleRegular, models.RoleAdmin:
+	default:
+		return query, fmt.Errorf("unknown role %q", query.Role)
+	}
+	switch query.Disabled {
+	case "", "active", "disabled":
+	default:
+		return query, fmt.Errorf("unknown status %q", query.Disabled)
+	}
+
+	var err error
+	if query.After, err = positiveParam(values, "after"); err != nil {
+		return query, err
+	}
+	if query.Before, err = positiveParam(values, "before"); err != nil {
+		return query, err
+	}
+	if query.After != 0 && query.Before != 0 {
+		return query, errors.New("after and before cannot be combined")
+	}
+	return query, nil
+}
+
+// userPageCursors returns the cursors for the pages around page, empty where
+// there is no such page.
+func userPageCursors(page *store.UserPage) (prev, next string) {
+	if len(page.Users) == 0 {
+		return "", ""
+	}
+	if page.HasPrev {
+		prev = strconv.Itoa(page.Users[0].ID)
+	}
+	if page.HasNext {
+		next = strconv.Itoa(page.Users[len(page.Users)-1].ID)
+	}
+	return prev, next
+}
+
+// adminUsersHandler lists user accounts with the role and account actions
+// available for each, and the latest changes admins made to accounts
+func (h *Handler) adminUsersHandler(w http.ResponseWriter, r *http.Request) {
+	user := middleware.UserFromContext(r.Context())
+
+	query, err := parseUserQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	page, err := h.users.List(r.Context(), query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	auditLog, err := h.users.ListAudit(r.Context(), 0, auditEntriesShown)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	prev, next := userPageCursors(page)
+	data := PageData{
+		Title:    "User Management",
+		User:     user,
+		Error:    r.URL.Query().Get("error"),
+		Users:    page.Users,
+		AuditLog: auditLog,
+		Query:    r.URL.Query(),
+		PrevPage: pageURL("/admin/users", r.URL.Query(), "before", prev),
+		NextPage: pageURL("/admin/users", r.URL.Query(), "after", next),
+	}
+
+	tmpl, err := template.ParseFiles(
+		"templates/base.html",
+		"templates/admin/users.html",
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// manageUserHandler promotes, demotes, disables or enables a user account
+func (h *Handler) manageUserHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	admin := middleware.UserFromContext(r.Context())
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	if id == admin.ID {
+		// Admins cannot lock themselves out
+		redirectWithError(w, r, "/admin/users", "You cannot change your own account")
+		return
+	}
+
+	var err error
+	switch action := r.FormValue("action"); action {
+	case "promote":
+		err = h.users.SetRole(r.Context(), admin.ID, id, models.RoleAdmin)
+	case "demote":
+		err = h.users.SetRole(r.Context(), admin.ID, id, models.RoleRegular)
+	case "disable":
+		err = h.users.SetDisabled(r.Context(), admin.ID, id, true)
+	case "enable":
+		err = h.users.SetDisabled(r.Context(), admin.ID, id, false)
+	default:
+		http.Error(w, "Unknown user action", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if errors.Is(err, store.ErrConflict) {
+		redirectWithError(w, r, "/admin/users", fmt.Sprintf("User %d already has that role or status", id))
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+}
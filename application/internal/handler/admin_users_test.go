@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/url"
+	"testing"
+
+	"online-judge/internal/models"
+	"online-judge/internal/store"
+)
+
+func TestParseUserQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  url.Values
+		want    store.UserQuery
+		wantErr bool
+	}{
+		{
+			name:   "defaults",
+			values: url.Values{},
+			want:   store.UserQuery{Limit: usersPerPage},
+		},
+		{
+			name:   "filters",
+			values: url.Values{"q": {" alice "}, "role": {"admin"}, "status": {"disabled"}, "after": {"7"}},
+			want:   store.UserQuery{Search: "alice", Role: models.RoleAdmin, Disabled: "disabled", After: 7, Limit: usersPerPage},
+		},
+		{
+			name:   "previous page",
+			values: url.Values{"status": {"active"}, "before": {"30"}},
+			want:   store.UserQuery{Disabled: "active", Before: 30, Limit: usersPerPage},
+		},
+		{name: "unknown role", values: url.Values{"role": {"root"}}, wantErr: true},
+		{name: "unknown status", values: url.Values{"status": {"banned"}}, wantErr: true},
+		{name: "bad cursor", values: url.Values{"after": {"-1"}}, wantErr: true},
+		{name: "both cursors", values: url.Values{"after": {"1"}, "before": {"9"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseUserQuery(tt.values)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseUserQuery(%v) = %+v, want an error", tt.values, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseUserQuery(%v) failed: %v", tt.values, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseUserQuery(%v) = %+v, want %+v", tt.values, got, tt.want)
+			}
+		})
+	}
+}
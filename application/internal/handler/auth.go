@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"online-judge/internal/auth"
+	"online-judge/internal/middleware"
+	"online-judge/internal/models"
+	"online-judge/internal/store"
+)
+
+func (h *Handler) loginHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "GET" {
+		data := PageData{
+			Title: "Sign In",
+			Next:  r.URL.Query().Get("next"),
+		}
+
+		tmpl, err := template.ParseFiles(
+			"templates/base.html",
+			"templates/login.html",
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if r.Method == "POST" {
+		username := r.FormValue("username")
+		password := r.FormValue("password")
+
+		user, err := h.users.GetByUsername(r.Context(), username)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if user == nil {
+			err = h.passwords.VerifyDummy(password)
+		} else {
+			err = h.passwords.Verify(user.PasswordHash, password)
+		}
+		if err != nil {
+			data := PageData{
+				Title: "Sign In",
+				Error: "Invalid username or password",
+				Next:  r.FormValue("next"),
+			}
+
+			tmpl, err := template.ParseFiles(
+				"templates/base.html",
+				"templates/login.html",
+			)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		if user.IsDisabled() {
+			data := PageData{
+				Title: "Sign In",
+				Error: "This account has been disabled. Please contact an administrator.",
+				Next:  r.FormValue("next"),
+			}
+
+			tmpl, err := template.ParseFiles(
+				"templates/base.html",
+				"templates/login.html",
+			)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		h.rehashPassword(r.Context(), user, password)
+
+		if err := h.sessions.Start(r.Context(), w, r, user.ID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		http.Redirect(w, r, safeRedirect(r.FormValue("next"), "/dashboard"), http.StatusSeeOther)
+	}
+}
+
+// safeRedirect returns target if it is a local path, and fallback otherwise,
+// so that the login form cannot be used as an open redirect
+func safeRedirect(target, fallback string) string {
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") || strings.HasPrefix(target, "/\\") {
+		return fallback
+	}
+	return target
+}
+
+func (h *Handler) registerHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "GET" {
+		data := PageData{
+			Title: "Create Account",
+		}
+		tmpl, err := template.ParseFiles(
+			"templates/base.html",
+			"templates/register.html",
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if r.Method == "POST" {
+		username := r.FormValue("username")
+		email := r.FormValue("email")
+		password := r.FormValue("password")
+		confirmPassword := r.FormValue("confirm_password")
+
+		if problem := registrationProblem(password, confirmPassword); problem != "" {
+			data := PageData{
+				Title: "Create Account",
+				Error: problem,
+			}
+
+			tmpl, err := template.ParseFiles(
+				"templates/base.html",
+				"templates/register.html",
+			)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		passwordHash, err := h.passwords.Hash(password)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		err = h.users.Create(r.Context(), &models.User{
+			Username:     username,
+			Email:        email,
+			PasswordHash: passwordHash,
+			Role:         models.RoleRegular, // Default role for new users
+		})
+		if errors.Is(err, store.ErrDuplicate) {
+			data := PageData{
+				Title: "Create Account",
+				Error: "Username or email already exists",
+			}
+
+			tmpl, err := template.ParseFiles(
+				"templates/base.html",
+				"templates/register.html",
+			)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+	}
+}
+
+// registrationProblem checks a new account's password against the same
+// rules as the profile form, returning "" if it is acceptable.
+func registrationProblem(password, confirmPassword string) string {
+	switch {
+	case utf8.RuneCountInString(password) < auth.MinPasswordLength:
+		return fmt.Sprintf("Password must be at least %d characters", auth.MinPasswordLength)
+	case len(password) > auth.MaxPasswordBytes:
+		return fmt.Sprintf("Password must be at most %d bytes", auth.MaxPasswordBytes)
+	case password != confirmPassword:
+		return "Passwords do not match"
+	}
+	return ""
+}
+
+// logoutHandler revokes the current session. It is only routed for POST, so
+// that a link or image elsewhere cannot sign the user out.
+func (h *Handler) logoutHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.sessions.End(r.Context(), w, r); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// logoutAllHandler revokes every session of the current user, on all devices
+func (h *Handler) logoutAllHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user := middleware.UserFromContext(r.Context())
+
+	if err := h.sessions.EndAll(r.Context(), w, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+// rehashPassword upgrades a user's stored hash after a successful login when
+// the configured bcrypt cost has changed. Failures are logged, not fatal.
+func (h *Handler) rehashPassword(ctx context.Context, user *models.User, password string) {
+	if !h.passwords.NeedsRehash(user.PasswordHash) {
+		return
+	}
+
+	passwordHash, err := h.passwords.Hash(password)
+	if err != nil {
+		log.Printf("Error rehashing password for %s: %v", user.Username, err)
+		return
+	}
+
+	user.PasswordHash = passwordHash
+	if err := h.users.Update(ctx, user); err != nil {
+		log.Printf("Error saving rehashed password for %s: %v", user.Username, err)
+	}
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSafeRedirect(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"/questions?page=2", "/questions?page=2"},
+		{"", "/dashboard"},
+		{"https://evil.example", "/dashboard"},
+		{"//evil.example", "/dashboard"},
+		{"/\\evil.example", "/dashboard"},
+	}
+
+	for _, tt := range tests {
+		if got := safeRedirect(tt.target, "/dashboard"); got != tt.want {
+			t.Errorf("safeRedirect(%q) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestRegistrationProblem(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		confirm  string
+		want     string
+	}{
+		{"acceptable", "correct horse", "correct horse", ""},
+		{"empty", "", "", "at least 8 characters"},
+		{"too short", "short", "short", "at least 8 characters"},
+		{"too long for bcrypt", strings.Repeat("x", 73), strings.Repeat("x", 73), "at most 72 bytes"},
+		{"mismatch", "correct horse", "battery staple", "do not match"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := registrationProblem(tt.password, tt.confirm)
+			if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+				t.Errorf("registrationProblem = %q, want it to mention %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// Package handler implements the HTTP handlers of the web server.
+package handler
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+	"github.com/jmoiron/sqlx"
+
+	"online-judge/internal/auth"
+	"online-judge/internal/languages"
+	"online-judge/internal/middleware"
+	"online-judge/internal/models"
+	"online-judge/internal/queue"
+	"online-judge/internal/stats"
+	"online-judge/internal/store"
+)
+
+// PageData is the data passed to every page template.
+type PageData struct {
+	Title     string
+	Error     string
+	Next      string
+	User      *models.User
+	Questions []models.Question
+	Question  *models.Question
+	// Statement is the question's statement rendered from Markdown.
+	Statement   template.HTML
+	Submissions []models.Submission
+	// Query holds the request's query parameters, for filter forms.
+	Query url.Values
+	// Form holds submitted form values, to fill a form in again after it
+	// was refused.
+	Form url.Values
+	// PrevPage and NextPage link the pages around a paginated list; they
+	// are empty on the first and last page.
+	PrevPage string
+	NextPage string
+	// Stats is the statistics section of the profile page.
+	Stats *profileStats
+	// Users and AuditLog fill the admin user management page.
+	Users    []models.User
+	AuditLog []models.UserAuditEntry
+	// Languages are offered by the submission form and, for custom
+	// checkers, by the question editor.
+	Languages []languages.Language
+	// Checkers are offered by the question editor.
+	Checkers []models.CheckerType
+	// TestResults are the per-test results on the submission page.
+	TestResults []models.TestResult
+}
+
+// Options are the services a Handler depends on besides the database.
+type Options struct {
+	Passwords *auth.PasswordHasher
+	Sessions  *auth.SessionManager
+	// Jobs backs the internal jobs API used by runners; the API is only
+	// served when RunnerToken is set.
+	Jobs        queue.Source
+	RunnerToken string
+	// Notifier is told about every new submission, waking runners waiting
+	// in a claim. Without it runners find new submissions by polling.
+	Notifier *queue.Notifier
+	// Events streams the progress of submissions to the pages watching
+	// them. Without it those pages reconnect to learn about changes.
+	Events *queue.Events
+	// Languages are those submissions may be written in.
+	Languages *languages.Registry
+}
+
+// Handler holds the repositories and services shared by all handlers.
+type Handler struct {
+	users       *store.UserStore
+	questions   *store.QuestionStore
+	testCases   *store.TestCaseStore
+	submissions *store.SubmissionStore
+	passwords   *auth.PasswordHasher
+	sessions    *auth.SessionManager
+	notifier    *queue.Notifier
+	events      *queue.Events
+	stats       *stats.Cache
+	languages   *languages.Registry
+	jobs        *jobsAPI
+}
+
+// New creates a Handler whose repositories are backed by db.
+func New(db *sqlx.DB, opts Options) *Handler {
+	submissions := store.NewSubmissionStore(db)
+	h := &Handler{
+		users:       store.NewUserStore(db),
+		questions:   store.NewQuestionStore(db),
+		testCases:   store.NewTestCaseStore(db),
+		submissions: submissions,
+		stats:       stats.NewCache(submissions),
+		passwords:   opts.Passwords,
+		sessions:    opts.Sessions,
+		notifier:    opts.Notifier,
+		events:      opts.Events,
+		languages:   opts.Languages,
+	}
+	if opts.RunnerToken != "" {
+		h.jobs = &jobsAPI{source: opts.Jobs, token: opts.RunnerToken, notifier: opts.Notifier, events: opts.Events}
+	}
+	return h
+}
+
+// Routes returns the router serving all pages and static files.
+func (h *Handler) Routes() http.Handler {
+	router := mux.NewRouter()
+
+	// Serve static files
+	fs := http.FileServer(http.Dir("static"))
+	router.PathPrefix("/static/").Handler(http.StripPrefix("/static/", fs))
+
+	// Public routes
+	router.HandleFunc("/", h.homeHandler)
+	router.HandleFunc("/login", h.loginHandler)
+	router.HandleFunc("/register", h.registerHandler)
+	router.HandleFunc("/logout", h.logoutHandler).Methods(http.MethodPost)
+
+	// Routes for signed-in users
+	router.Handle("/logout/all", loggedIn(h.logoutAllHandler))
+	router.Handle("/dashboard", loggedIn(h.dashboardHandler))
+	router.Handle("/questions", loggedIn(h.questionsHandler))
+	router.Handle("/api/questions", loggedIn(h.questionsAPIHandler))
+	router.Handle("/questions/mine", loggedIn(h.myQuestionsHandler))
+	router.Handle("/questions/create", loggedIn(h.createQuestionHandler))
+	router.Handle("/questions/{id:[0-9]+}", loggedIn(h.questionHandler))
+	router.Handle("/questions/{id:[0-9]+}/edit", loggedIn(h.editQuestionHandler))
+	router.Handle("/questions/{id:[0-9]+}/review", loggedIn(h.requestReviewHandler))
+	router.Handle("/questions/submit", loggedIn(h.submitQuestionHandler))
+	router.Handle("/submissions", loggedIn(h.submissionsHandler))
+	router.Handle("/submissions/{id:[0-9]+}", loggedIn(h.submissionHandler))
+	router.Handle("/submissions/{id:[0-9]+}/events", loggedIn(h.submissionEventsHandler))
+	router.Handle("/profile", loggedIn(h.profileHandler))
+	router.Handle("/api/profile/stats", loggedIn(h.profileStatsAPIHandler))
+
+	// Admin routes
+	router.Handle("/admin/failures", adminOnly(h.failuresHandler))
+	router.Handle("/admin/questions", adminOnly(h.adminQuestionsHandler))
+	router.Handle("/admin/questions/{id:[0-9]+}/review", adminOnly(h.reviewQuestionHandler))
+	router.Handle("/admin/users", adminOnly(h.adminUsersHandler))
+	router.Handle("/admin/users/{id:[0-9]+}", adminOnly(h.manageUserHandler))
+
+	return middleware.LoadUser(h.currentUser)(router)
+}
+
+// InternalRoutes returns the router serving the internal API for runners,
+// meant for a listener only they can reach. It serves nothing when no
+// runner token is configured.
+func (h *Handler) InternalRoutes() http.Handler {
+	router := mux.NewRouter()
+	if h.jobs != nil {
+		h.jobs.routes(router.PathPrefix("/internal").Subrouter())
+	}
+	return router
+}
+
+// loggedIn restricts a handler to signed-in users.
+func loggedIn(handler http.HandlerFunc) http.Handler {
+	return middleware.RequireLogin(handler)
+}
+
+// adminOnly restricts a handler to admins.
+func adminOnly(handler http.HandlerFunc) http.Handler {
+	return middleware.RequireRole(models.RoleAdmin)(handler)
+}
+
+// currentUser returns the owner of the request's session, or nil if there is none
+func (h *Handler) currentUser(r *http.Request) (*models.User, error) {
+	session, err := h.sessions.Resolve(r.Context(), r)
+	if errors.Is(err, auth.ErrNoSession) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := h.users.GetByID(r.Context(), session.UserID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Disabling revokes the user's sessions; this covers requests racing it
+	if user.IsDisabled() {
+		return nil, nil
+	}
+	return user, nil
+}
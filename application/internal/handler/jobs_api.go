@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"online-judge/internal/middleware"
+	"online-judge/internal/models"
+	"online-judge/internal/queue"
+)
+
+// maxJobRequestBytes bounds request bodies of the internal jobs API, the
+// largest being results: the stdout, stderr and message excerpts of every
+// test and the message of the verdict, any byte of which JSON may escape to
+// six (\u0001), plus room for the other fields.
+const maxJobRequestBytes = 6*(3*models.MaxTestCases*models.MaxExcerptBytes+models.MaxMessageBytes) + 1<<20
+
+// maxClaimWait bounds how long a claim is held open waiting for a submission.
+const maxClaimWait = 25 * time.Second
+
+// jobsAPI serves the internal API through which runners claim submissions
+// and report verdicts without access to the database.
+type jobsAPI struct {
+	source queue.Source
+	token  string
+	// notifier, if set, lets claims wait for new submissions.
+	notifier *queue.Notifier
+	// events, if set, receives the progress of every submission.
+	events *queue.Events
+}
+
+// routes registers the API on router; every endpoint requires the runner token.
+func (api *jobsAPI) routes(router *mux.Router) {
+	router.Use(api.requireToken)
+	router.HandleFunc("/jobs/claim", api.claimHandler).Methods("POST")
+	router.HandleFunc("/jobs/{id:[0-9]+}/heartbeat", api.heartbeatHandler).Methods("POST")
+	router.HandleFunc("/jobs/{id:[0-9]+}/progress", api.progressHandler).Methods("POST")
+	router.HandleFunc("/jobs/{id:[0-9]+}/result", api.resultHandler).Methods("POST")
+}
+
+func (api *jobsAPI) requireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(token), []byte(api.token)) != 1 {
+			middleware.WriteJSONError(w, http.StatusUnauthorized, "invalid runner token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (api *jobsAPI) claimHandler(w http.ResponseWriter, r *http.Request) {
+	var req queue.ClaimRequest
+	if !decodeJobRequest(w, r, &req, &req.WorkerID) {
+		return
+	}
+
+	wait := min(time.Duration(req.WaitMS)*time.Millisecond, maxClaimWait)
+	job, err := api.claim(r.Context(), req.WorkerID, req.Languages, wait)
+	if errors.Is(err, queue.ErrNoJob) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		log.Printf("Error claiming job for %s: %v", req.WorkerID, err)
+		middleware.WriteJSONError(w, http.StatusInternalServerError, "could not claim a job")
+		return
+	}
+	api.publish(queue.Event{
+		Kind:         queue.EventStatus,
+		SubmissionID: job.SubmissionID,
+		Status:       models.SubmissionProcessing,
+		Total:        len(job.TestCases),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(job); err != nil {
+		log.Printf("Error writing job %d: %v", job.SubmissionID, err)
+	}
+}
+
+// claim claims a job for workerID. When no submission is pending it waits up
+// to wait for one to be created, instead of making the runner poll again.
+func (api *jobsAPI) claim(ctx context.Context, workerID string, languages []string, wait time.Duration) (*queue.Job, error) {
+	if api.notifier == nil || wait <= 0 {
+		return api.source.Claim(ctx, workerID, languages)
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		wake := api.notifier.Wait()
+		job, err := api.source.Claim(ctx, workerID, languages)
+		if !errors.Is(err, queue.ErrNoJob) || api.notifier.Closed() {
+			return job, err
+		}
+
+		// Every waiting runner wakes up; those that lose the race wait again
+		select {
+		case <-wake:
+		case <-timer.C:
+			return nil, queue.ErrNoJob
+		case <-ctx.Done():
+			return nil, queue.ErrNoJob
+		}
+	}
+}
+
+func (api *jobsAPI) heartbeatHandler(w http.ResponseWriter, r *http.Request) {
+	var req queue.HeartbeatRequest
+	if !decodeJobRequest(w, r, &req, &req.WorkerID) {
+		return
+	}
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	acknowledge(w, api.source.Heartbeat(r.Context(), id, req.WorkerID))
+}
+
+func (api *jobsAPI) progressHandler(w http.ResponseWriter, r *http.Request) {
+	var req queue.ProgressRequest
+	if !decodeJobRequest(w, r, &req, &req.WorkerID) {
+		return
+	}
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	err := api.source.Progress(r.Context(), id, req.WorkerID, req.Passed, req.Total)
+	if err == nil {
+		api.publish(queue.Event{
+			Kind:         queue.EventProgress,
+			SubmissionID: id,
+			Status:       models.SubmissionProcessing,
+			Passed:       req.Passed,
+			Total:        req.Total,
+		})
+	}
+	acknowledge(w, err)
+}
+
+func (api *jobsAPI) resultHandler(w http.ResponseWriter, r *http.Request) {
+	var req queue.ResultRequest
+	if !decodeJobRequest(w, r, &req, &req.WorkerID) {
+		return
+	}
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	// The verdict is recorded and published as derived from the test results.
+	req.Verdict.AggregateTests()
+	err := api.source.Report(r.Context(), id, req.WorkerID, req.Verdict)
+	if err == nil {
+		api.publish(queue.Event{
+			Kind:            queue.EventVerdict,
+			SubmissionID:    id,
+			Status:          models.SubmissionCompleted,
+			Result:          req.Verdict.Result,
+			ExecutionTimeMS: req.Verdict.ExecutionTimeMS,
+			MemoryUsageMB:   req.Verdict.MemoryUsageMB,
+			FailedTest:      req.Verdict.FailedTest,
+		})
+	}
+	acknowledge(w, err)
+}
+
+// publish passes event on to the clients watching its submission.
+func (api *jobsAPI) publish(event queue.Event) {
+	if api.events != nil {
+		api.events.Publish(event)
+	}
+}
+
+// decodeJobRequest reads the JSON body of a request made on behalf of workerID.
+func decodeJobRequest(w http.ResponseWriter, r *http.Request, req any, workerID *string) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJobRequestBytes)).Decode(req); err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return false
+	}
+	if *workerID == "" {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "worker_id is required")
+		return false
+	}
+	return true
+}
+
+// acknowledge answers a heartbeat or result: 204 on success, 409 if the
+// worker no longer holds the submission.
+func acknowledge(w http.ResponseWriter, err error) {
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, queue.ErrLeaseLost):
+		middleware.WriteJSONError(w, http.StatusConflict, "submission is not held by this worker")
+	default:
+		log.Printf("Error updating job: %v", err)
+		middleware.WriteJSONError(w, http.StatusInternalServerError, "could not update the job")
+	}
+}
@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"online-judge/internal/models"
+	"online-judge/internal/queue"
+)
+
+// fakeSource holds a single job that one worker at a time may lease.
+type fakeSource struct {
+	mu      sync.Mutex
+	job     *queue.Job
+	holder  string
+	verdict *models.Verdict
+	// languages are those of the last claim
+	languages []string
+}
+
+func (s *fakeSource) Claim(_ context.Context, workerID string, languages []string) (*queue.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.languages = languages
+	if s.job == nil || s.holder != "" {
+		return nil, queue.ErrNoJob
+	}
+	s.holder = workerID
+	return s.job, nil
+}
+
+func (s *fakeSource) Heartbeat(_ context.Context, submissionID int, workerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.job == nil || s.job.SubmissionID != submissionID || s.holder != workerID {
+		return queue.ErrLeaseLost
+	}
+	return nil
+}
+
+func (s *fakeSource) Progress(ctx context.Context, submissionID int, workerID string, _, _ int) error {
+	return s.Heartbeat(ctx, submissionID, workerID)
+}
+
+func (s *fakeSource) Report(ctx context.Context, submissionID int, workerID string, verdict models.Verdict) error {
+	if err := s.Heartbeat(ctx, submissionID, workerID); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.verdict = &verdict
+	return nil
+}
+
+func newJobsServer(t *testing.T, source queue.Source, events *queue.Events) *httptest.Server {
+	t.Helper()
+
+	h := &Handler{jobs: &jobsAPI{source: source, token: "runner-secret", events: events}}
+	server := httptest.NewServer(h.InternalRoutes())
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestJobsAPIRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source := &fakeSource{job: &queue.Job{
+		SubmissionID:  7,
+		Code:          "package main",
+		TimeLimitMS:   1000,
+		MemoryLimitMB: 256,
+		TestCases:     []models.TestCase{{Input: "5 7", ExpectedOutput: "12"}},
+	}}
+	client := queue.NewClient(newJobsServer(t, source, nil).URL, "runner-secret")
+
+	job, err := client.Claim(ctx, "runner-1", []string{"go", "cpp"})
+	if err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	if len(source.languages) != 2 || source.languages[0] != "go" || source.languages[1] != "cpp" {
+		t.Errorf("Claim sent languages %v, want [go cpp]", source.languages)
+	}
+	if job.SubmissionID != 7 || len(job.TestCases) != 1 || job.TestCases[0].ExpectedOutput != "12" {
+		t.Errorf("Claim returned %+v", job)
+	}
+
+	if _, err := client.Claim(ctx, "runner-2", nil); !errors.Is(err, queue.ErrNoJob) {
+		t.Errorf("second Claim = %v, want ErrNoJob", err)
+	}
+	if err := client.Heartbeat(ctx, 7, "runner-1"); err != nil {
+		t.Errorf("Heartbeat: %v", err)
+	}
+	if err := client.Heartbeat(ctx, 7, "runner-2"); !errors.Is(err, queue.ErrLeaseLost) {
+		t.Errorf("Heartbeat by another worker = %v, want ErrLeaseLost", err)
+	}
+
+	verdict := models.Verdict{Result: models.ResultWrongAnswer, ExecutionTimeMS: 12, MemoryUsageMB: 3, FailedTest: 1,
+		Tests: []models.TestResult{{TestNumber: 1, Result: models.ResultWrongAnswer, ExecutionTimeMS: 12, MemoryUsageMB: 3,
+			Stdout: "41\n", Message: "line 1 differs from the expected output"}}}
+	if err := client.Report(ctx, 7, "runner-1", verdict); err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if source.verdict == nil || !reflect.DeepEqual(*source.verdict, verdict) {
+		t.Errorf("recorded verdict = %+v, want %+v", source.verdict, verdict)
+	}
+}
+
+func TestJobsAPIRejectsWrongToken(t *testing.T) {
+	source := &fakeSource{job: &queue.Job{SubmissionID: 1}}
+	client := queue.NewClient(newJobsServer(t, source, nil).URL, "guessed")
+
+	if _, err := client.Claim(context.Background(), "runner-1", nil); err == nil {
+		t.Fatal("Claim with a wrong token succeeded")
+	}
+	if source.holder != "" {
+		t.Error("job was leased despite the wrong token")
+	}
+}
+
+func TestJobsAPIClaimWaitsForSubmission(t *testing.T) {
+	source := &fakeSource{}
+	api := &jobsAPI{source: source, notifier: queue.NewNotifier()}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		source.mu.Lock()
+		source.job = &queue.Job{SubmissionID: 3}
+		source.mu.Unlock()
+		api.notifier.Notify()
+	}()
+
+	job, err := api.claim(context.Background(), "runner-1", nil, 5*time.Second)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if job.SubmissionID != 3 {
+		t.Errorf("claimed submission %d, want 3", job.SubmissionID)
+	}
+}
+
+func TestJobsAPIClaimGivesUp(t *testing.T) {
+	tests := []struct {
+		name  string
+		wait  time.Duration
+		close bool
+	}{
+		{name: "wait elapses", wait: 20 * time.Millisecond},
+		{name: "server shuts down", wait: time.Minute, close: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &jobsAPI{source: &fakeSource{}, notifier: queue.NewNotifier()}
+			if tt.close {
+				time.AfterFunc(20*time.Millisecond, api.notifier.Close)
+			}
+
+			start := time.Now()
+			if _, err := api.claim(context.Background(), "runner-1", nil, tt.wait); !errors.Is(err, queue.ErrNoJob) {
+				t.Errorf("claim = %v, want ErrNoJob", err)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("claim returned after %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestJobsAPIPublishesEvents(t *testing.T) {
+	ctx := context.Background()
+	source := &fakeSource{job: &queue.Job{
+		SubmissionID: 7,
+		TestCases:    []models.TestCase{{Input: "1"}, {Input: "2"}},
+	}}
+	events := queue.NewEvents()
+	watched, unsubscribe := events.Subscribe(7)
+	defer unsubscribe()
+	client := queue.NewClient(newJobsServer(t, source, events).URL, "runner-secret")
+
+	if _, err := client.Claim(ctx, "runner-1", nil); err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	if err := client.Progress(ctx, 7, "runner-1", 1, 2); err != nil {
+		t.Fatalf("Progress: %v", err)
+	}
+	if err := client.Progress(ctx, 7, "runner-2", 2, 2); !errors.Is(err, queue.ErrLeaseLost) {
+		t.Errorf("Progress by another worker = %v, want ErrLeaseLost", err)
+	}
+	// The published verdict is derived from the test results.
+	verdict := models.Verdict{Result: models.ResultOK, Tests: []models.TestResult{
+		{TestNumber: 1, Result: models.ResultOK, ExecutionTimeMS: 5, MemoryUsageMB: 1},
+		{TestNumber: 2, Result: models.ResultWrongAnswer, ExecutionTimeMS: 3, MemoryUsageMB: 2},
+	}}
+	if err := client.Report(ctx, 7, "runner-1", verdict); err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if source.verdict == nil || source.verdict.Result != models.ResultWrongAnswer || source.verdict.FailedTest != 2 {
+		t.Errorf("recorded verdict = %+v, want the one derived from the tests", source.verdict)
+	}
+
+	want := []queue.Event{
+		{Kind: queue.EventStatus, SubmissionID: 7, Status: models.SubmissionProcessing, Total: 2},
+		{Kind: queue.EventProgress, SubmissionID: 7, Status: models.SubmissionProcessing, Passed: 1, Total: 2},
+		{Kind: queue.EventVerdict, SubmissionID: 7, Status: models.SubmissionCompleted,
+			Result: models.ResultWrongAnswer, ExecutionTimeMS: 5, MemoryUsageMB: 2, FailedTest: 2},
+	}
+	for i, w := range want {
+		select {
+		case got := <-watched:
+			if got != w {
+				t.Errorf("event %d = %+v, want %+v", i, got, w)
+			}
+		default:
+			t.Fatalf("event %d was not published", i)
+		}
+	}
+	select {
+	case got := <-watched:
+		t.Errorf("unexpected event %+v", got)
+	default:
+	}
+}
+
+func TestJobsAPIAcceptsLargestResult(t *testing.T) {
+	source := &fakeSource{job: &queue.Job{SubmissionID: 7}}
+	client := queue.NewClient(newJobsServer(t, source, nil).URL, "runner-secret")
+	ctx := context.Background()
+
+	if _, err := client.Claim(ctx, "runner-1", nil); err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	// Control characters are escaped to six bytes each.
+	escaped := strings.Repeat("\x01", models.MaxExcerptBytes) + "…"
+	verdict := models.Verdict{ErrorMessage: strings.Repeat("\x01", models.MaxMessageBytes)}
+	for i := 1; i <= models.MaxTestCases; i++ {
+		verdict.Tests = append(verdict.Tests, models.TestResult{TestNumber: i, Result: models.ResultOK,
+			Stdout: escaped, Stderr: escaped, Message: escaped})
+	}
+	if err := client.Report(ctx, 7, "runner-1", verdict); err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if source.verdict == nil {
+		t.Fatal("no verdict was recorded")
+	}
+	if len(source.verdict.Tests) != models.MaxTestCases {
+		t.Errorf("recorded verdict has %d tests, want %d", len(source.verdict.Tests), models.MaxTestCases)
+	}
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"online-judge/internal/middleware"
+	"online-judge/internal/models"
+	"online-judge/internal/store"
+)
+
+func (h *Handler) homeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	data := PageData{
+		Title: "Welcome to Our Platform",
+		User:  middleware.UserFromContext(r.Context()),
+	}
+
+	tmpl, err := template.ParseFiles(
+		"templates/base.html",
+		"templates/home.html",
+	)
+	if err != nil {
+		log.Printf("Error parsing templates: %v", err) // Log the detailed error
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+		log.Printf("Error executing template: %v", err) // Log execution errors too
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) dashboardHandler(w http.ResponseWriter, r *http.Request) {
+	user := middleware.UserFromContext(r.Context())
+
+	data := PageData{
+		Title: "Dashboard",
+		User:  user,
+	}
+
+	tmpl, err := template.ParseFiles(
+		"templates/base.html",
+		"templates/user-dashboard/dashboard.html",
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// profileHandler shows the profile form and saves the user's email, full
+// name and, when the current password is given, a new password
+func (h *Handler) profileHandler(w http.ResponseWriter, r *http.Request) {
+	user := middleware.UserFromContext(r.Context())
+
+	if r.Method == "GET" {
+		h.showProfile(w, r, user, "", nil)
+		return
+	}
+	if r.Method != "POST" {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	update, problems := parseProfileForm(r.PostForm)
+	if len(problems) > 0 {
+		h.showProfile(w, r, user, strings.Join(problems, "\n"), r.PostForm)
+		return
+	}
+
+	updated := *user
+	updated.Email = update.Email
+	updated.FullName = update.FullName
+	if update.NewPassword != "" {
+		if h.passwords.Verify(user.PasswordHash, update.CurrentPassword) != nil {
+			h.showProfile(w, r, user, "Current password is incorrect", r.PostForm)
+			return
+		}
+		passwordHash, err := h.passwords.Hash(update.NewPassword)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		updated.PasswordHash = passwordHash
+	}
+
+	err := h.users.Update(r.Context(), &updated)
+	if errors.Is(err, store.ErrDuplicate) {
+		h.showProfile(w, r, user, "That email is already used by another account", r.PostForm)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if update.NewPassword == "" {
+		http.Redirect(w, r, "/profile?saved=profile", http.StatusSeeOther)
+		return
+	}
+
+	// Whoever knew the old password may still be signed in elsewhere
+	if err := h.sessions.EndOthers(r.Context(), r, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/profile?saved=password", http.StatusSeeOther)
+}
+
+// showProfile renders the profile form, filled in from form after a refused
+// update and from the user otherwise.
+func (h *Handler) showProfile(w http.ResponseWriter, r *http.Request, user *models.User, problem string, form url.Values) {
+	userStats, err := h.stats.Get(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := PageData{
+		Title: "Profile",
+		Error: problem,
+		User:  user,
+		Query: r.URL.Query(),
+		Form:  form,
+		Stats: newProfileStats(userStats, time.Now()),
+	}
+
+	tmpl, err := template.ParseFiles(
+		"templates/base.html",
+		"templates/user-dashboard/profile.html",
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
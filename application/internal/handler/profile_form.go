@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"online-judge/internal/auth"
+)
+
+const (
+	// maxEmailLength and maxFullNameLength match the users table's columns.
+	maxEmailLength    = 255
+	maxFullNameLength = 255
+)
+
+// profileUpdate is a validated submission of the profile form.
+type profileUpdate struct {
+	Email    string
+	FullName string
+	// CurrentPassword and NewPassword are set when the password is to be
+	// changed.
+	CurrentPassword string
+	NewPassword     string
+}
+
+// parseProfileForm reads the profile form, or describes every problem with it.
+// The password is only changed when one of the password fields is filled in.
+func parseProfileForm(form url.Values) (profileUpdate, []string) {
+	update := profileUpdate{
+		Email:    strings.TrimSpace(form.Get("email")),
+		FullName: strings.TrimSpace(form.Get("full_name")),
+	}
+	var problems []string
+
+	switch address, err := mail.ParseAddress(update.Email); {
+	case update.Email == "":
+		problems = append(problems, "Email is required")
+	case err != nil || address.Address != update.Email:
+		problems = append(problems, "Email must be a valid address such as name@example.com")
+	case len(update.Email) > maxEmailLength:
+		problems = append(problems, fmt.Sprintf("Email must be at most %d characters", maxEmailLength))
+	}
+
+	switch {
+	case update.FullName == "":
+		problems = append(problems, "Full name is required")
+	case utf8.RuneCountInString(update.FullName) > maxFullNameLength:
+		problems = append(problems, fmt.Sprintf("Full name must be at most %d characters", maxFullNameLength))
+	}
+
+	current, next, confirm := form.Get("current_password"), form.Get("new_password"), form.Get("confirm_password")
+	if current == "" && next == "" && confirm == "" {
+		return update, problems
+	}
+
+	switch {
+	case current == "":
+		problems = append(problems, "Enter your current password to change it")
+	case next == "":
+		problems = append(problems, "Enter a new password")
+	case utf8.RuneCountInString(next) < auth.MinPasswordLength:
+		problems = append(problems, fmt.Sprintf("New password must be at least %d characters", auth.MinPasswordLength))
+	case len(next) > auth.MaxPasswordBytes:
+		problems = append(problems, fmt.Sprintf("New password must be at most %d bytes", auth.MaxPasswordBytes))
+	case next != confirm:
+		problems = append(problems, "New passwords do not match")
+	}
+	update.CurrentPassword, update.NewPassword = current, next
+	return update, problems
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestParseProfileForm(t *testing.T) {
+	base := func(changes ...string) url.Values {
+		form := url.Values{"email": {" ada@example.com "}, "full_name": {" Ada Lovelace "}}
+		for i := 0; i+1 < len(changes); i += 2 {
+			form.Set(changes[i], changes[i+1])
+		}
+		return form
+	}
+
+	tests := []struct {
+		name         string
+		form         url.Values
+		wantPassword string
+		wantProblem  string
+	}{
+		{name: "profile only", form: base()},
+		{
+			name:         "password change",
+			form:         base("current_password", "old-secret", "new_password", "new-secret", "confirm_password", "new-secret"),
+			wantPassword: "new-secret",
+		},
+		{name: "missing email", form: base("email", " "), wantProblem: "Email is required"},
+		{name: "invalid email", form: base("email", "ada"), wantProblem: "valid address"},
+		{name: "display name in email", form: base("email", "Ada <ada@example.com>"), wantProblem: "valid address"},
+		{name: "long email", form: base("email", strings.Repeat("a", 250)+"@example.com"), wantProblem: "at most 255"},
+		{name: "missing full name", form: base("full_name", ""), wantProblem: "Full name is required"},
+		{
+			name:        "no current password",
+			form:        base("new_password", "new-secret", "confirm_password", "new-secret"),
+			wantProblem: "current password",
+		},
+		{name: "no new password", form: base("current_password", "old-secret"), wantProblem: "Enter a new password"},
+		{
+			name:        "short password",
+			form:        base("current_password", "old-secret", "new_password", "short", "confirm_password", "short"),
+			wantProblem: "at least 8",
+		},
+		{
+			name: "long password",
+			form: base("current_password", "old-secret",
+				"new_password", strings.Repeat("p", 73), "confirm_password", strings.Repeat("p", 73)),
+			wantProblem: "at most 72 bytes",
+		},
+		{
+			name:        "mismatched passwords",
+			form:        base("current_password", "old-secret", "new_password", "new-secret", "confirm_password", "new-secreT"),
+			wantProblem: "do not match",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update, problems := parseProfileForm(tt.form)
+			if tt.wantProblem == "" {
+				if len(problems) != 0 {
+					t.Fatalf("unexpected problems: %v", problems)
+				}
+				if update.Email != "ada@example.com" || update.FullName != "Ada Lovelace" {
+					t.Errorf("email/full name = %q/%q", update.Email, update.FullName)
+				}
+				if update.NewPassword != tt.wantPassword {
+					t.Errorf("new password = %q, want %q", update.NewPassword, tt.wantPassword)
+				}
+				return
+			}
+			if len(problems) != 1 || !strings.Contains(problems[0], tt.wantProblem) {
+				t.Errorf("problems = %q, want one mentioning %q", problems, tt.wantProblem)
+			}
+		})
+	}
+}
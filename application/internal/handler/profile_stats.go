@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"online-judge/internal/middleware"
+	"online-judge/internal/models"
+	"online-judge/internal/stats"
+)
+
+// verdictOrder is the order of the profile's verdict breakdown.
+var verdictOrder = []models.SubmissionResult{
+	models.ResultOK,
+	models.ResultWrongAnswer,
+	models.ResultTimeLimitExceeded,
+	models.ResultMemoryLimitExceeded,
+	models.ResultRuntimeError,
+	models.ResultCompileError,
+	models.ResultSystemError,
+}
+
+// profileStats is the statistics section of the profile page.
+type profileStats struct {
+	*models.UserStats
+	// AcceptancePercent is AcceptanceRate as a whole percentage.
+	AcceptancePercent int
+	VerdictBars       []verdictBar
+	// Heatmap holds the activity as columns of weeks, Sunday first.
+	Heatmap [][]heatmapDay
+}
+
+// verdictBar is one row of the verdict breakdown.
+type verdictBar struct {
+	Result  models.SubmissionResult
+	Count   int
+	Percent int // share of all verdicts
+}
+
+// heatmapDay is one cell of the activity heatmap. Cells outside the covered
+// days have no Date.
+type heatmapDay struct {
+	Date        string
+	Submissions int
+	Level       int // 0 for no activity up to 4 for the busiest days
+}
+
+// newProfileStats prepares stats for display on today's profile page.
+func newProfileStats(stats *models.UserStats, today time.Time) *profileStats {
+	view := &profileStats{
+		UserStats:         stats,
+		AcceptancePercent: int(stats.AcceptanceRate*100 + 0.5),
+		Heatmap:           activityHeatmap(stats.Activity, today),
+	}
+
+	total := 0
+	for _, count := range stats.Verdicts {
+		total += count
+	}
+	for _, result := range verdictOrder {
+		bar := verdictBar{Result: result, Count: stats.Verdicts[result]}
+		if total > 0 {
+			bar.Percent = bar.Count * 100 / total
+		}
+		view.VerdictBars = append(view.VerdictBars, bar)
+	}
+	return view
+}
+
+// activityHeatmap lays out the last stats.ActivityDays days up to today as
+// weeks.
+func activityHeatmap(activity []models.DayActivity, today time.Time) [][]heatmapDay {
+	counts := make(map[string]int, len(activity))
+	for _, day := range activity {
+		counts[day.Date] = day.Submissions
+	}
+
+	today = today.UTC().Truncate(24 * time.Hour)
+	first := today.AddDate(0, 0, 1-stats.ActivityDays)
+	start := first.AddDate(0, 0, -int(first.Weekday()))
+
+	var weeks [][]heatmapDay
+	for day := start; !day.After(today); day = day.AddDate(0, 0, 7) {
+		week := make([]heatmapDay, 7)
+		for i := range week {
+			date := day.AddDate(0, 0, i)
+			if date.Before(first) || date.After(today) {
+				continue
+			}
+			key := date.Format("2006-01-02")
+			week[i] = heatmapDay{Date: key, Submissions: counts[key], Level: activityLevel(counts[key])}
+		}
+		weeks = append(weeks, week)
+	}
+	return weeks
+}
+
+func activityLevel(submissions int) int {
+	switch {
+	case submissions == 0:
+		return 0
+	case submissions <= 2:
+		return 1
+	case submissions <= 5:
+		return 2
+	case submissions <= 9:
+		return 3
+	}
+	return 4
+}
+
+// profileStatsAPIHandler serves the current user's statistics as JSON
+func (h *Handler) profileStatsAPIHandler(w http.ResponseWriter, r *http.Request) {
+	user := middleware.UserFromContext(r.Context())
+
+	userStats, err := h.stats.Get(r.Context(), user.ID)
+	if err != nil {
+		log.Printf("Error computing statistics of user %d: %v", user.ID, err)
+		middleware.WriteJSONError(w, http.StatusInternalServerError, "could not compute statistics")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(userStats); err != nil {
+		log.Printf("Error writing statistics of user %d: %v", user.ID, err)
+	}
+}
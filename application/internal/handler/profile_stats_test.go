@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"online-judge/internal/models"
+	"online-judge/internal/stats"
+)
+
+func TestActivityHeatmap(t *testing.T) {
+	// A Friday
+	today := time.Date(2026, 10, 16, 15, 4, 5, 0, time.UTC)
+	activity := []models.DayActivity{
+		{Date: "2026-10-16", Submissions: 12},
+		{Date: "2026-10-11", Submissions: 1},
+		{Date: "2025-10-17", Submissions: 4},
+	}
+
+	weeks := activityHeatmap(activity, today)
+
+	days := 0
+	for _, week := range weeks {
+		if len(week) != 7 {
+			t.Fatalf("week has %d days, want 7", len(week))
+		}
+		for _, day := range week {
+			if day.Date != "" {
+				days++
+			}
+		}
+	}
+	if days != stats.ActivityDays {
+		t.Errorf("heatmap covers %d days, want %d", days, stats.ActivityDays)
+	}
+
+	first := weeks[0][5]
+	if first.Date != "2025-10-17" || first.Submissions != 4 || first.Level != 2 {
+		t.Errorf("first day = %+v, want 2025-10-17 with 4 submissions at level 2", first)
+	}
+	if weeks[0][4].Date != "" {
+		t.Errorf("day before the covered range = %+v, want blank", weeks[0][4])
+	}
+
+	last := weeks[len(weeks)-1]
+	if last[0].Date != "2026-10-11" || last[0].Level != 1 {
+		t.Errorf("last Sunday = %+v, want 2026-10-11 at level 1", last[0])
+	}
+	if last[5].Date != "2026-10-16" || last[5].Level != 4 {
+		t.Errorf("today = %+v, want 2026-10-16 at level 4", last[5])
+	}
+	if last[6].Date != "" {
+		t.Errorf("day after today = %+v, want blank", last[6])
+	}
+}
+
+func TestNewProfileStats(t *testing.T) {
+	userStats := &models.UserStats{
+		AcceptanceRate: 0.425,
+		Verdicts: map[models.SubmissionResult]int{
+			models.ResultOK:          3,
+			models.ResultWrongAnswer: 1,
+		},
+	}
+
+	view := newProfileStats(userStats, time.Now())
+
+	if view.AcceptancePercent != 43 {
+		t.Errorf("AcceptancePercent = %d, want 43", view.AcceptancePercent)
+	}
+	if len(view.VerdictBars) != len(verdictOrder) {
+		t.Fatalf("got %d verdict bars, want %d", len(view.VerdictBars), len(verdictOrder))
+	}
+	want := map[models.SubmissionResult]verdictBar{
+		models.ResultOK:          {Result: models.ResultOK, Count: 3, Percent: 75},
+		models.ResultWrongAnswer: {Result: models.ResultWrongAnswer, Count: 1, Percent: 25},
+	}
+	for i, bar := range view.VerdictBars {
+		w := want[verdictOrder[i]]
+		w.Result = verdictOrder[i]
+		if bar != w {
+			t.Errorf("bar %d = %+v, want %+v", i, bar, w)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package handler
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"online-judge/internal/languages"
+	"online-judge/internal/models"
+)
+
+// Bounds enforced on the question editor form.
+const (
+	maxTitleLength       = 255 // questions.title is VARCHAR(255)
+	maxStatementBytes    = 64 << 10
+	maxTestCases         = models.MaxTestCases
+	maxTestCaseBytes     = 1 << 20
+	maxQuestionFormBytes = 8 << 20
+
+	minTimeLimitMS     = 100
+	maxTimeLimitMS     = 10000
+	defaultTimeLimitMS = 1000
+
+	maxTags = 10
+
+	minMemoryLimitMB     = 16
+	maxMemoryLimitMB     = models.MaxMemoryLimitMB
+	defaultMemoryLimitMB = 256
+
+	defaultCheckerEpsilon = 1e-6
+	maxCheckerEpsilon     = 1
+)
+
+var difficulties = map[string]bool{"easy": true, "medium": true, "hard": true}
+
+// tagPattern is the form of a single normalized tag, e.g. "dp" or "c++".
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9+#.-]{0,29}$`)
+
+// testCaseField matches the indexed test case fields of the form, such as
+// test_cases[3][input]. Indices need not be contiguous because cases can be
+// removed in the browser.
+var testCaseField = regexp.MustCompile(`^test_cases\[(\d{1,6})\]\[(input|output|is_sample)\]$`)
+
+// newQuestionDraft returns the question shown in an empty editor form.
+func newQuestionDraft() *models.Question {
+	return &models.Question{
+		Difficulty:     "easy",
+		TimeLimitMS:    defaultTimeLimitMS,
+		MemoryLimitMB:  defaultMemoryLimitMB,
+		CheckerType:    models.CheckerWhitespace,
+		CheckerEpsilon: defaultCheckerEpsilon,
+		TestCases:      []models.TestCase{{}},
+	}
+}
+
+// parseQuestionForm builds a question from the submitted editor form and
+// lists the problems found with it, in a form fit to show to the user. The
+// question is returned even when validation fails, so that the form can be
+// shown again with the values the user entered. A custom checker or an
+// interactor must be written in one of the enabled languages.
+func parseQuestionForm(form url.Values, enabled *languages.Registry) (*models.Question, []string) {
+	question := &models.Question{
+		Title:       strings.TrimSpace(form.Get("title")),
+		Statement:   normalizeNewlines(strings.TrimSpace(form.Get("description"))),
+		Difficulty:  form.Get("difficulty"),
+		Tags:        splitTags(form.Get("tags")),
+		Status:      models.QuestionDraft,
+		Interactive: form.Get("interactive") != "",
+		TestCases:   parseTestCases(form),
+	}
+
+	var problems []string
+	var problem string
+	question.TimeLimitMS, problem = parseLimit(form.Get("time_limit_ms"), "Time limit", minTimeLimitMS, maxTimeLimitMS, "ms")
+	if problem != "" {
+		problems = append(problems, problem)
+	}
+	question.MemoryLimitMB, problem = parseLimit(form.Get("memory_limit_mb"), "Memory limit", minMemoryLimitMB, maxMemoryLimitMB, "MB")
+	if problem != "" {
+		problems = append(problems, problem)
+	}
+
+	switch {
+	case question.Title == "":
+		problems = append(problems, "Title is required")
+	case utf8.RuneCountInString(question.Title) > maxTitleLength:
+		problems = append(problems, fmt.Sprintf("Title must be at most %d characters", maxTitleLength))
+	}
+
+	switch {
+	case question.Statement == "":
+		problems = append(problems, "Description is required")
+	case len(question.Statement) > maxStatementBytes:
+		problems = append(problems, fmt.Sprintf("Description must be at most %d KB", maxStatementBytes>>10))
+	}
+
+	if !difficulties[question.Difficulty] {
+		problems = append(problems, "Difficulty must be easy, medium or hard")
+	}
+
+	if len(question.Tags) > maxTags {
+		problems = append(problems, fmt.Sprintf("A question can have at most %d tags", maxTags))
+	}
+	for _, tag := range question.Tags {
+		if !tagPattern.MatchString(tag) {
+			problems = append(problems, fmt.Sprintf("Tag %q must be at most 30 letters, digits or +#.- characters", tag))
+		}
+	}
+
+	switch {
+	case len(question.TestCases) == 0:
+		problems = append(problems, "At least one test case is required")
+	case len(question.TestCases) > maxTestCases:
+		problems = append(problems, fmt.Sprintf("A question can have at most %d test cases", maxTestCases))
+	}
+	for i, testCase := range question.TestCases {
+		// An interactor may not need an answer besides the input.
+		if strings.TrimSpace(testCase.ExpectedOutput) == "" && !question.Interactive {
+			problems = append(problems, fmt.Sprintf("Test case %d: expected output is required", i+1))
+		}
+		if len(testCase.Input) > maxTestCaseBytes || len(testCase.ExpectedOutput) > maxTestCaseBytes {
+			problems = append(problems, fmt.Sprintf("Test case %d: input and output must be at most %d MB each", i+1, maxTestCaseBytes>>20))
+		}
+	}
+
+	if question.Interactive {
+		problems = append(problems, parseInteractor(form, question, enabled)...)
+	} else {
+		problems = append(problems, parseChecker(form, question, enabled)...)
+	}
+
+	return question, problems
+}
+
+// parseInteractor reads the interactor of an interactive question, which
+// leaves the question with the default checker.
+func parseInteractor(form url.Values, question *models.Question, enabled *languages.Registry) []string {
+	question.CheckerType = models.CheckerWhitespace
+	question.CheckerEpsilon = defaultCheckerEpsilon
+	question.InteractorLanguage = form.Get("interactor_language")
+	question.InteractorSource = form.Get("interactor_source")
+
+	var problems []string
+	if _, ok := enabled.Get(question.InteractorLanguage); !ok {
+		problems = append(problems, "Please choose a supported language for the interactor")
+	}
+	switch {
+	case strings.TrimSpace(question.InteractorSource) == "":
+		problems = append(problems, "An interactive question needs the source code of its interactor")
+	case len(question.InteractorSource) > maxSourceBytes:
+		problems = append(problems, fmt.Sprintf("Interactor source must be at most %d KB", maxSourceBytes>>10))
+	}
+	return problems
+}
+
+// parseChecker reads the checker fields of the form into question. The
+// epsilon is read only for the float checker, and the program only for a
+// custom checker.
+func parseChecker(form url.Values, question *models.Question, enabled *languages.Registry) []string {
+	question.CheckerType = models.CheckerType(form.Get("checker"))
+	question.CheckerEpsilon = defaultCheckerEpsilon
+	if question.CheckerType == "" {
+		question.CheckerType = models.CheckerWhitespace
+	}
+	if !slices.Contains(models.CheckerTypes, question.CheckerType) {
+		return []string{"Please choose one of the offered checkers"}
+	}
+
+	var problems []string
+	switch question.CheckerType {
+	case models.CheckerFloat:
+		epsilon, err := strconv.ParseFloat(strings.TrimSpace(form.Get("checker_epsilon")), 64)
+		if err != nil || !(epsilon > 0 && epsilon <= maxCheckerEpsilon) {
+			problems = append(problems, fmt.Sprintf("Checker epsilon must be a number greater than 0 and at most %d", maxCheckerEpsilon))
+		}
+		question.CheckerEpsilon = epsilon
+	case models.CheckerCustom:
+		question.CheckerLanguage = form.Get("checker_language")
+		question.CheckerSource = form.Get("checker_source")
+		if _, ok := enabled.Get(question.CheckerLanguage); !ok {
+			problems = append(problems, "Please choose a supported language for the checker")
+		}
+		switch {
+		case strings.TrimSpace(question.CheckerSource) == "":
+			problems = append(problems, "A custom checker needs its source code")
+		case len(question.CheckerSource) > maxSourceBytes:
+			problems = append(problems, fmt.Sprintf("Checker source must be at most %d KB", maxSourceBytes>>10))
+		}
+	}
+	return problems
+}
+
+// parseTestCases collects the indexed test case fields in index order.
+// Cases whose input and output are both blank are dropped, since they are
+// usually a result of clicking "Add Test Case" once too often.
+func parseTestCases(form url.Values) []models.TestCase {
+	byIndex := make(map[int]*models.TestCase)
+	for key, values := range form {
+		match := testCaseField.FindStringSubmatch(key)
+		if match == nil || len(values) == 0 {
+			continue
+		}
+		index, _ := strconv.Atoi(match[1])
+		testCase, ok := byIndex[index]
+		if !ok {
+			testCase = &models.TestCase{}
+			byIndex[index] = testCase
+		}
+		switch match[2] {
+		case "input":
+			testCase.Input = normalizeNewlines(values[0])
+		case "output":
+			testCase.ExpectedOutput = normalizeNewlines(values[0])
+		case "is_sample":
+			testCase.IsSample = values[0] != ""
+		}
+	}
+
+	indices := make([]int, 0, len(byIndex))
+	for index, testCase := range byIndex {
+		if strings.TrimSpace(testCase.Input) == "" && strings.TrimSpace(testCase.ExpectedOutput) == "" {
+			continue
+		}
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+
+	testCases := make([]models.TestCase, 0, len(indices))
+	for _, index := range indices {
+		testCases = append(testCases, *byIndex[index])
+	}
+	return testCases
+}
+
+// splitTags splits a comma- or space-separated list into distinct lower-case
+// tags, keeping their order.
+func splitTags(s string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	tags := make([]string, 0, len(fields))
+	seen := make(map[string]bool)
+	for _, tag := range fields {
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// parseLimit parses an integer limit within [min, max], returning a problem
+// description if the value is missing or out of range.
+func parseLimit(value, name string, min, max int, unit string) (int, string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, name + " is required"
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return n, fmt.Sprintf("%s must be between %d and %d %s", name, min, max, unit)
+	}
+	return n, ""
+}
+
+// normalizeNewlines converts the CRLF line endings browsers send for
+// textareas into the LF line endings programs expect on stdin.
+func normalizeNewlines(s string) string {
+	return strings.ReplaceAll(s, "\r\n", "\n")
+}
@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"online-judge/internal/config"
+	"online-judge/internal/languages"
+	"online-judge/internal/models"
+)
+
+func questionLanguages(t *testing.T) *languages.Registry {
+	t.Helper()
+	enabled, err := languages.NewRegistry(config.RunnerConfig{EnabledLanguages: []string{"cpp", "python3"}})
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	return enabled
+}
+
+func validQuestionForm() url.Values {
+	return url.Values{
+		"title":                    {"  Sum of Two Numbers "},
+		"description":              {"Print a+b.\r\n"},
+		"difficulty":               {"easy"},
+		"tags":                     {"Math, basics math"},
+		"time_limit_ms":            {"1000"},
+		"memory_limit_mb":          {"256"},
+		"test_cases[0][input]":     {"1 2\r\n"},
+		"test_cases[0][output]":    {"3\r\n"},
+		"test_cases[0][is_sample]": {"1"},
+		"test_cases[5][input]":     {"10 20"},
+		"test_cases[5][output]":    {"30"},
+		"test_cases[2][input]":     {"-1 1"},
+		"test_cases[2][output]":    {"0"},
+	}
+}
+
+func TestParseQuestionForm(t *testing.T) {
+	question, problems := parseQuestionForm(validQuestionForm(), questionLanguages(t))
+	if len(problems) != 0 {
+		t.Fatalf("unexpected problems: %v", problems)
+	}
+
+	if question.Title != "Sum of Two Numbers" || question.Statement != "Print a+b." {
+		t.Errorf("title/statement = %q/%q", question.Title, question.Statement)
+	}
+	if question.TimeLimitMS != 1000 || question.MemoryLimitMB != 256 {
+		t.Errorf("limits = %d ms/%d MB", question.TimeLimitMS, question.MemoryLimitMB)
+	}
+	if !reflect.DeepEqual([]string(question.Tags), []string{"math", "basics"}) {
+		t.Errorf("tags = %q", question.Tags)
+	}
+	if question.Status != models.QuestionDraft {
+		t.Errorf("status = %q, want draft", question.Status)
+	}
+	if question.CheckerType != models.CheckerWhitespace || question.CheckerEpsilon != defaultCheckerEpsilon {
+		t.Errorf("checker = %q (epsilon %g), want whitespace", question.CheckerType, question.CheckerEpsilon)
+	}
+
+	want := []models.TestCase{
+		{Input: "1 2\n", ExpectedOutput: "3\n", IsSample: true},
+		{Input: "-1 1", ExpectedOutput: "0"},
+		{Input: "10 20", ExpectedOutput: "30"},
+	}
+	if !reflect.DeepEqual(question.TestCases, want) {
+		t.Errorf("test cases = %+v, want %+v", question.TestCases, want)
+	}
+}
+
+func TestParseQuestionFormProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(url.Values)
+		problem string
+	}{
+		{"missing title", func(f url.Values) { f.Set("title", " ") }, "Title is required"},
+		{"long title", func(f url.Values) { f.Set("title", strings.Repeat("x", 256)) }, "Title must be at most"},
+		{"missing description", func(f url.Values) { f.Del("description") }, "Description is required"},
+		{"bad difficulty", func(f url.Values) { f.Set("difficulty", "extreme") }, "Difficulty must be"},
+		{"missing time limit", func(f url.Values) { f.Del("time_limit_ms") }, "Time limit is required"},
+		{"time limit too low", func(f url.Values) { f.Set("time_limit_ms", "5") }, "Time limit must be between"},
+		{"memory limit not a number", func(f url.Values) { f.Set("memory_limit_mb", "lots") }, "Memory limit must be between"},
+		{"bad tag", func(f url.Values) { f.Set("tags", "dp, <b>") }, `Tag "<b>" must be`},
+		{"too many tags", func(f url.Values) { f.Set("tags", "a b c d e f g h i j k") }, "A question can have at most 10 tags"},
+		{"missing output", func(f url.Values) { f.Set("test_cases[2][output]", " ") }, "Test case 2: expected output is required"},
+		{"no test cases", func(f url.Values) {
+			for key := range f {
+				if strings.HasPrefix(key, "test_cases") {
+					f.Del(key)
+				}
+			}
+		}, "At least one test case is required"},
+		{"unknown checker", func(f url.Values) { f.Set("checker", "regex") }, "Please choose one of the offered checkers"},
+		{"missing epsilon", func(f url.Values) { f.Set("checker", "float") }, "Checker epsilon must be"},
+		{"epsilon too large", func(f url.Values) {
+			f.Set("checker", "float")
+			f.Set("checker_epsilon", "2")
+		}, "Checker epsilon must be"},
+		{"checker in a disabled language", func(f url.Values) {
+			f.Set("checker", "custom")
+			f.Set("checker_language", "go")
+			f.Set("checker_source", "package main")
+		}, "Please choose a supported language for the checker"},
+		{"checker without source", func(f url.Values) {
+			f.Set("checker", "custom")
+			f.Set("checker_language", "cpp")
+		}, "A custom checker needs its source code"},
+		{"checker source too large", func(f url.Values) {
+			f.Set("checker", "custom")
+			f.Set("checker_language", "cpp")
+			f.Set("checker_source", strings.Repeat("a", maxSourceBytes+1))
+		}, "Checker source must be at most"},
+		{"interactor in a disabled language", func(f url.Values) {
+			f.Set("interactive", "1")
+			f.Set("interactor_language", "go")
+			f.Set("interactor_source", "package main")
+		}, "Please choose a supported language for the interactor"},
+		{"interactor without source", func(f url.Values) {
+			f.Set("interactive", "1")
+			f.Set("interactor_language", "cpp")
+		}, "An interactive question needs the source code of its interactor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := validQuestionForm()
+			tt.modify(form)
+
+			_, problems := parseQuestionForm(form, questionLanguages(t))
+			if len(problems) != 1 || !strings.HasPrefix(problems[0], tt.problem) {
+				t.Errorf("problems = %q, want one starting with %q", problems, tt.problem)
+			}
+		})
+	}
+}
+
+func TestParseQuestionFormCheckers(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields map[string]string
+		want   models.Checker
+	}{
+		{
+			name:   "float keeps its epsilon",
+			fields: map[string]string{"checker": "float", "checker_epsilon": "1e-4", "checker_source": "ignored"},
+			want:   models.Checker{Type: models.CheckerFloat, Epsilon: 1e-4},
+		},
+		{
+			name:   "other checkers use the default epsilon",
+			fields: map[string]string{"checker": "tokens", "checker_epsilon": "0.5"},
+			want:   models.Checker{Type: models.CheckerTokens, Epsilon: defaultCheckerEpsilon},
+		},
+		{
+			name:   "custom keeps its program",
+			fields: map[string]string{"checker": "custom", "checker_language": "python3", "checker_source": "exit(0)\n"},
+			want:   models.Checker{Type: models.CheckerCustom, Epsilon: defaultCheckerEpsilon, Language: "python3", Source: "exit(0)\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := validQuestionForm()
+			for key, value := range tt.fields {
+				form.Set(key, value)
+			}
+
+			question, problems := parseQuestionForm(form, questionLanguages(t))
+			if len(problems) != 0 {
+				t.Fatalf("unexpected problems: %v", problems)
+			}
+			if got := question.Checker(); got != tt.want {
+				t.Errorf("Checker() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuestionFormInteractive(t *testing.T) {
+	form := validQuestionForm()
+	form.Set("interactive", "1")
+	form.Set("interactor_language", "cpp")
+	form.Set("interactor_source", "int main() {}")
+	form.Set("checker", "custom")
+	form.Set("checker_language", "python3")
+	form.Set("checker_source", "exit(0)")
+	form.Set("test_cases[2][output]", "")
+
+	question, problems := parseQuestionForm(form, questionLanguages(t))
+	if len(problems) != 0 {
+		t.Fatalf("unexpected problems: %v", problems)
+	}
+
+	want := &models.Interactor{Language: "cpp", Source: "int main() {}"}
+	if got := question.Interactor(); got == nil || *got != *want {
+		t.Errorf("Interactor() = %+v, want %+v", got, want)
+	}
+	if got := question.Checker(); got != (models.Checker{Type: models.CheckerWhitespace, Epsilon: defaultCheckerEpsilon}) {
+		t.Errorf("Checker() = %+v, want the default checker", got)
+	}
+	if len(question.TestCases) != 3 || question.TestCases[1].ExpectedOutput != "" {
+		t.Errorf("test cases = %+v, want the case without output kept", question.TestCases)
+	}
+}
+
+func TestParseTestCasesDropsBlankCases(t *testing.T) {
+	form := url.Values{
+		"test_cases[0][input]":     {""},
+		"test_cases[0][output]":    {"hello"},
+		"test_cases[1][input]":     {"  "},
+		"test_cases[1][output]":    {""},
+		"test_cases[1][is_sample]": {"1"},
+		"test_cases[x][input]":     {"ignored"},
+	}
+
+	got := parseTestCases(form)
+	want := []models.TestCase{{ExpectedOutput: "hello"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTestCases = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"online-judge/internal/models"
+	"online-judge/internal/store"
+)
+
+// questionsPerPage is the page size of the question list.
+const questionsPerPage = 10
+
+// maxSearchLength bounds the title search of the question list.
+const maxSearchLength = 100
+
+// parseQuestionQuery reads the question list's query parameters:
+//
+//	difficulty  easy, medium or hard
+//	tags        comma-separated; questions must have all of them
+//	solved      "solved" or "unsolved", relative to userID
+//	q           case-insensitive title search
+//	sort        "newest" (default) or "oldest"
+//	after       cursor of the page's predecessor, from next links
+//	before      cursor of the page's successor, from previous links
+func parseQuestionQuery(values url.Values, userID int) (store.QuestionQuery, error) {
+	query := store.QuestionQuery{
+		Difficulty: values.Get("difficulty"),
+		Tags:       splitTags(values.Get("tags")),
+		Search:     strings.TrimSpace(values.Get("q")),
+		UserID:     userID,
+		Limit:      questionsPerPage,
+	}
+
+	if query.Difficulty != "" && !difficulties[query.Difficulty] {
+		return query, fmt.Errorf("unknown difficulty %q", query.Difficulty)
+	}
+	if len(query.Search) > maxSearchLength {
+		return query, fmt.Errorf("search must be at most %d characters", maxSearchLength)
+	}
+
+	switch values.Get("solved") {
+	case "":
+	case "solved":
+		query.Solved = new(bool)
+		*query.Solved = true
+	case "unsolved":
+		query.Solved = new(bool)
+	default:
+		return query, errors.New(`solved must be "solved" or "unsolved"`)
+	}
+
+	switch values.Get("sort") {
+	case "", "newest":
+	case "oldest":
+		query.Oldest = true
+	default:
+		return query, errors.New(`sort must be "newest" or "oldest"`)
+	}
+
+	var err error
+	if token := values.Get("after"); token != "" {
+		if query.After, err = store.ParseQuestionCursor(token); err != nil {
+			return query, err
+		}
+	}
+	if token := values.Get("before"); token != "" {
+		if query.After != nil {
+			return query, errors.New("after and before cannot be combined")
+		}
+		if query.Before, err = store.ParseQuestionCursor(token); err != nil {
+			return query, err
+		}
+	}
+	return query, nil
+}
+
+// pageCursors returns the cursors for the pages around page, empty where
+// there is no such page.
+func pageCursors(page *store.QuestionPage) (prev, next string) {
+	if len(page.Questions) == 0 {
+		return "", ""
+	}
+	if page.HasPrev {
+		prev = page.First().String()
+	}
+	if page.HasNext {
+		next = page.Last().String()
+	}
+	return prev, next
+}
+
+// pageURL returns path with the request's query parameters, moved to the page
+// next to the cursor in the direction given by key ("after" or "before").
+func pageURL(path string, values url.Values, key, cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+	query := url.Values{}
+	for k, v := range values {
+		if k != "after" && k != "before" {
+			query[k] = v
+		}
+	}
+	query.Set(key, cursor)
+	return path + "?" + query.Encode()
+}
+
+// questionListResponse is the JSON form of a page of the question list.
+type questionListResponse struct {
+	Questions  []questionSummary `json:"questions"`
+	PrevCursor string            `json:"prev_cursor,omitempty"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// questionSummary is a question as shown in the list, without its statement.
+type questionSummary struct {
+	ID            int       `json:"id"`
+	Title         string    `json:"title"`
+	Difficulty    string    `json:"difficulty"`
+	Tags          []string  `json:"tags"`
+	TimeLimitMS   int       `json:"time_limit_ms"`
+	MemoryLimitMB int       `json:"memory_limit_mb"`
+	Owner         string    `json:"owner"`
+	PublishedAt   time.Time `json:"published_at"`
+	Solved        bool      `json:"solved"`
+}
+
+func newQuestionListResponse(page *store.QuestionPage) questionListResponse {
+	response := questionListResponse{Questions: make([]questionSummary, 0, len(page.Questions))}
+	response.PrevCursor, response.NextCursor = pageCursors(page)
+	for _, q := range page.Questions {
+		response.Questions = append(response.Questions, summarizeQuestion(q))
+	}
+	return response
+}
+
+func summarizeQuestion(q models.Question) questionSummary {
+	summary := questionSummary{
+		ID:            q.ID,
+		Title:         q.Title,
+		Difficulty:    q.Difficulty,
+		Tags:          q.Tags,
+		TimeLimitMS:   q.TimeLimitMS,
+		MemoryLimitMB: q.MemoryLimitMB,
+		Owner:         q.OwnerUsername,
+		Solved:        q.Solved,
+	}
+	if summary.Tags == nil {
+		summary.Tags = []string{}
+	}
+	if q.PublishedAt != nil {
+		summary.PublishedAt = *q.PublishedAt
+	}
+	return summary
+}
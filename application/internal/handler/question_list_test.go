@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"online-judge/internal/store"
+)
+
+func TestParseQuestionQuery(t *testing.T) {
+	cursor := store.QuestionCursor{PublishedAt: time.Unix(1700000000, 0).UTC(), ID: 9}
+
+	query, err := parseQuestionQuery(url.Values{
+		"difficulty": {"medium"},
+		"tags":       {"DP, graphs dp"},
+		"solved":     {"unsolved"},
+		"q":          {"  path "},
+		"sort":       {"oldest"},
+		"after":      {cursor.String()},
+	}, 4)
+	if err != nil {
+		t.Fatalf("parseQuestionQuery: %v", err)
+	}
+
+	if query.Difficulty != "medium" || query.Search != "path" || !query.Oldest || query.UserID != 4 {
+		t.Errorf("query = %+v", query)
+	}
+	if !reflect.DeepEqual(query.Tags, []string{"dp", "graphs"}) {
+		t.Errorf("tags = %q", query.Tags)
+	}
+	if query.Solved == nil || *query.Solved {
+		t.Errorf("solved = %v, want false", query.Solved)
+	}
+	if query.After == nil || query.After.ID != 9 || query.Before != nil {
+		t.Errorf("after/before = %+v/%+v", query.After, query.Before)
+	}
+	if query.Limit != questionsPerPage {
+		t.Errorf("limit = %d, want %d", query.Limit, questionsPerPage)
+	}
+}
+
+func TestParseQuestionQueryRejects(t *testing.T) {
+	cursor := store.QuestionCursor{ID: 1}.String()
+
+	tests := []url.Values{
+		{"difficulty": {"extreme"}},
+		{"solved": {"maybe"}},
+		{"sort": {"title"}},
+		{"after": {"garbage!"}},
+		{"after": {cursor}, "before": {cursor}},
+	}
+	for _, values := range tests {
+		if _, err := parseQuestionQuery(values, 1); err == nil {
+			t.Errorf("parseQuestionQuery(%v) succeeded, want an error", values)
+		}
+	}
+}
+
+func TestPageURL(t *testing.T) {
+	values := url.Values{"difficulty": {"easy"}, "before": {"old"}}
+
+	if got, want := pageURL("/questions", values, "after", "abc"), "/questions?after=abc&difficulty=easy"; got != want {
+		t.Errorf("pageURL = %q, want %q", got, want)
+	}
+	if got := pageURL("/questions", values, "after", ""); got != "" {
+		t.Errorf("pageURL without cursor = %q, want empty", got)
+	}
+}
@@ -0,0 +1,439 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"online-judge/internal/markdown"
+	"online-judge/internal/middleware"
+	"online-judge/internal/models"
+	"online-judge/internal/store"
+)
+
+// questionsHandler shows a page of the published question list
+func (h *Handler) questionsHandler(w http.ResponseWriter, r *http.Request) {
+	user := middleware.UserFromContext(r.Context())
+
+	query, err := parseQuestionQuery(r.URL.Query(), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	page, err := h.questions.ListPublished(r.Context(), query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	prev, next := pageCursors(page)
+	data := PageData{
+		Title:     "Questions",
+		User:      user,
+		Questions: page.Questions,
+		Query:     r.URL.Query(),
+		PrevPage:  pageURL("/questions", r.URL.Query(), "before", prev),
+		NextPage:  pageURL("/questions", r.URL.Query(), "after", next),
+	}
+
+	tmpl, err := template.ParseFiles(
+		"templates/base.html",
+		"templates/user-dashboard/questions.html",
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// questionsAPIHandler serves the same page of the question list as JSON
+func (h *Handler) questionsAPIHandler(w http.ResponseWriter, r *http.Request) {
+	user := middleware.UserFromContext(r.Context())
+
+	query, err := parseQuestionQuery(r.URL.Query(), user.ID)
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := h.questions.ListPublished(r.Context(), query)
+	if err != nil {
+		log.Printf("Error listing questions: %v", err)
+		middleware.WriteJSONError(w, http.StatusInternalServerError, "could not list questions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(newQuestionListResponse(page)); err != nil {
+		log.Printf("Error writing question list: %v", err)
+	}
+}
+
+// myQuestionsHandler lists the questions created by the current user in every status
+func (h *Handler) myQuestionsHandler(w http.ResponseWriter, r *http.Request) {
+	user := middleware.UserFromContext(r.Context())
+
+	questionsList, err := h.questions.ListByOwner(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := PageData{
+		Title:     "My Questions",
+		User:      user,
+		Questions: questionsList,
+	}
+
+	tmpl, err := template.ParseFiles(
+		"templates/base.html",
+		"templates/user-dashboard/my_questions.html",
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) createQuestionHandler(w http.ResponseWriter, r *http.Request) {
+	user := middleware.UserFromContext(r.Context())
+
+	question := newQuestionDraft()
+	question.OwnerID = user.ID
+	h.questionEditor(w, r, "Create Question", question, h.questions.CreateWithTestCases)
+}
+
+// editQuestionHandler lets the owner of a draft change it, test cases included
+func (h *Handler) editQuestionHandler(w http.ResponseWriter, r *http.Request) {
+	user := middleware.UserFromContext(r.Context())
+
+	question, ok := h.questionFromPath(w, r)
+	if !ok {
+		return
+	}
+	if !question.CanEdit(user) {
+		http.Error(w, "Only the owner of a draft question can edit it", http.StatusForbidden)
+		return
+	}
+
+	testCases, err := h.testCases.ListByQuestion(r.Context(), question.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	question.TestCases = testCases
+	if len(question.TestCases) == 0 {
+		question.TestCases = []models.TestCase{{}}
+	}
+
+	h.questionEditor(w, r, "Edit Question", question, h.questions.UpdateWithTestCases)
+}
+
+// questionEditor shows the question form filled in with question, and on POST
+// validates the submitted form and passes the result to save.
+func (h *Handler) questionEditor(w http.ResponseWriter, r *http.Request, title string, question *models.Question, save func(context.Context, *models.Question) error) {
+	data := PageData{
+		Title:     title,
+		User:      middleware.UserFromContext(r.Context()),
+		Question:  question,
+		Languages: h.languages.All(),
+		Checkers:  models.CheckerTypes,
+	}
+
+	if r.Method == "POST" {
+		r.Body = http.MaxBytesReader(w, r.Body, maxQuestionFormBytes)
+		if err := parseQuestionRequest(r); err != nil {
+			http.Error(w, "The question form could not be read: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		submitted, problems := parseQuestionForm(r.PostForm, h.languages)
+		submitted.ID = question.ID
+		submitted.OwnerID = question.OwnerID
+		submitted.ReviewComment = question.ReviewComment
+		if len(problems) == 0 {
+			err := save(r.Context(), submitted)
+			if errors.Is(err, store.ErrConflict) {
+				http.Error(w, "The question is no longer a draft and cannot be edited", http.StatusConflict)
+				return
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			http.Redirect(w, r, "/questions/mine", http.StatusSeeOther)
+			return
+		}
+
+		if len(submitted.TestCases) == 0 {
+			submitted.TestCases = []models.TestCase{{}}
+		}
+		data.Question = submitted
+		data.Error = strings.Join(problems, "\n")
+	}
+
+	tmpl, err := template.ParseFiles(
+		"templates/base.html",
+		"templates/user-dashboard/create_question.html",
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// programUploads maps the file inputs of the question form to the fields
+// holding the source code they can be used for instead.
+var programUploads = map[string]string{
+	"checker_file":    "checker_source",
+	"interactor_file": "interactor_source",
+}
+
+// parseQuestionRequest parses the question form, which is sent as multipart
+// when it may carry program files. An uploaded checker or interactor replaces
+// the source typed into the form.
+func parseQuestionRequest(r *http.Request) error {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return r.ParseForm()
+	}
+	if err := r.ParseMultipartForm(maxQuestionFormBytes); err != nil {
+		return err
+	}
+	for fileField, sourceField := range programUploads {
+		source, err := readUpload(r, fileField)
+		if err != nil {
+			return err
+		}
+		if len(source) > 0 {
+			r.PostForm.Set(sourceField, string(source))
+		}
+	}
+	return nil
+}
+
+// readUpload returns the content of the file uploaded in field, if any.
+func readUpload(r *http.Request, field string) ([]byte, error) {
+	file, _, err := r.FormFile(field)
+	if errors.Is(err, http.ErrMissingFile) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// Reading one byte past the limit lets the form report a file that is
+	// too large instead of silently cutting it.
+	return io.ReadAll(io.LimitReader(file, maxSourceBytes+1))
+}
+
+// requestReviewHandler sends one of the current user's drafts to the admins for review
+func (h *Handler) requestReviewHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user := middleware.UserFromContext(r.Context())
+
+	question, ok := h.questionFromPath(w, r)
+	if !ok {
+		return
+	}
+	if !question.CanEdit(user) {
+		http.Error(w, "Only the owner of a draft question can submit it for review", http.StatusForbidden)
+		return
+	}
+
+	err := h.questions.SetStatus(r.Context(), question.ID, models.QuestionDraft, models.QuestionReview, "")
+	if errors.Is(err, store.ErrConflict) {
+		http.Error(w, "The question is no longer a draft", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/questions/mine", http.StatusSeeOther)
+}
+
+// questionFromPath loads the question named by the route's id variable. It
+// answers 404 if there is no such question or the current user may not see
+// it, so that unpublished questions do not reveal their existence.
+func (h *Handler) questionFromPath(w http.ResponseWriter, r *http.Request) (*models.Question, bool) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	return h.visibleQuestion(w, r, id)
+}
+
+// visibleQuestion loads a question like questionFromPath, given its ID.
+func (h *Handler) visibleQuestion(w http.ResponseWriter, r *http.Request, id int) (*models.Question, bool) {
+	user := middleware.UserFromContext(r.Context())
+
+	question, err := h.questions.GetByID(r.Context(), id)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && !question.VisibleTo(user)) {
+		http.NotFound(w, r)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return question, true
+}
+
+// questionHandler shows a question with its statement, limits and sample tests
+func (h *Handler) questionHandler(w http.ResponseWriter, r *http.Request) {
+	question, ok := h.questionFromPath(w, r)
+	if !ok {
+		return
+	}
+	h.showQuestion(w, r, question, "", nil)
+}
+
+// showQuestion renders the question page. A refused submission passes the
+// reason and the submitted form, so the user gets their code back.
+func (h *Handler) showQuestion(w http.ResponseWriter, r *http.Request, question *models.Question, problem string, form url.Values) {
+	user := middleware.UserFromContext(r.Context())
+
+	// Hidden test cases never leave the database for anyone but the owner
+	var err error
+	if question.OwnerID == user.ID {
+		question.TestCases, err = h.testCases.ListByQuestion(r.Context(), question.ID)
+	} else {
+		question.TestCases, err = h.testCases.ListSamples(r.Context(), question.ID)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	statement, err := markdown.Render(question.Statement)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := PageData{
+		Title:     question.Title,
+		Error:     problem,
+		User:      user,
+		Question:  question,
+		Statement: statement,
+		Form:      form,
+		Languages: h.languages.All(),
+	}
+
+	tmpl, err := template.ParseFiles(
+		"templates/base.html",
+		"templates/user-dashboard/question.html",
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// submitQuestionHandler accepts a solution to a published question, queues
+// it for judging and sends the user to the submission's status page
+func (h *Handler) submitQuestionHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method == "GET" {
+		// The submission form lives on the question page
+		http.Redirect(w, r, "/questions/"+strconv.Itoa(id), http.StatusSeeOther)
+		return
+	}
+	if r.Method != "POST" {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user := middleware.UserFromContext(r.Context())
+	wantsJSON := middleware.WantsJSON(r)
+
+	question, ok := h.visibleQuestion(w, r, id)
+	if !ok {
+		return
+	}
+	if question.Status != models.QuestionPublished {
+		http.Error(w, "Submissions are only accepted for published questions", http.StatusForbidden)
+		return
+	}
+
+	refuse := func(problem string, form url.Values) {
+		if wantsJSON {
+			middleware.WriteJSONError(w, http.StatusUnprocessableEntity, problem)
+			return
+		}
+		h.showQuestion(w, r, question, problem, form)
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxSubmissionFormBytes)
+	if err := r.ParseForm(); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			refuse(fmt.Sprintf("Your code must be at most %d KB", maxSourceBytes>>10), nil)
+			return
+		}
+		http.Error(w, "The submission could not be read: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	submission, problem := parseSubmissionForm(r.PostForm, h.languages)
+	if problem != "" {
+		refuse(problem, r.PostForm)
+		return
+	}
+	submission.UserID = user.ID
+	submission.QuestionID = question.ID
+
+	if err := h.submissions.Create(r.Context(), submission); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if h.notifier != nil {
+		h.notifier.Notify()
+	}
+
+	location := "/submissions/" + strconv.Itoa(submission.ID)
+	if wantsJSON {
+		w.Header().Set("Location", location)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		response := submissionCreatedResponse{ID: submission.ID, Status: submission.Status, URL: location}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Printf("Error writing submission %d: %v", submission.ID, err)
+		}
+		return
+	}
+	http.Redirect(w, r, location, http.StatusSeeOther)
+}
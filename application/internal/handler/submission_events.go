@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"online-judge/internal/middleware"
+	"online-judge/internal/models"
+	"online-judge/internal/queue"
+	"online-judge/internal/store"
+)
+
+// eventKeepAlive is how often an idle event stream sends a comment, so that
+// proxies do not time it out.
+const eventKeepAlive = 15 * time.Second
+
+// eventRetryMS is how long browsers wait before reconnecting a dropped stream.
+const eventRetryMS = 3000
+
+// submissionEventsHandler streams the judging progress of a submission as
+// Server-Sent Events: its current state first, then every change pushed by
+// the jobs API, until the verdict.
+func (h *Handler) submissionEventsHandler(w http.ResponseWriter, r *http.Request) {
+	user := middleware.UserFromContext(r.Context())
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	// Subscribe before reading the submission so that no change in between
+	// is missed
+	var events <-chan queue.Event
+	if h.events != nil {
+		var unsubscribe func()
+		events, unsubscribe = h.events.Subscribe(id)
+		defer unsubscribe()
+	}
+
+	submission, err := h.submissions.GetByID(r.Context(), id)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && !submission.VisibleTo(user)) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	streamEvents(w, r, submissionEvent(submission), events)
+}
+
+// submissionEvent describes the current state of a submission.
+func submissionEvent(s *models.Submission) queue.Event {
+	event := queue.Event{Kind: queue.EventStatus, SubmissionID: s.ID, Status: s.Status}
+	if s.Status == models.SubmissionCompleted {
+		event.Kind = queue.EventVerdict
+		if s.Result != nil {
+			event.Result = *s.Result
+		}
+		if s.ExecutionTimeMS != nil {
+			event.ExecutionTimeMS = *s.ExecutionTimeMS
+		}
+		if s.MemoryUsageMB != nil {
+			event.MemoryUsageMB = *s.MemoryUsageMB
+		}
+		if s.FailedTest != nil {
+			event.FailedTest = *s.FailedTest
+		}
+	}
+	return event
+}
+
+// streamEvents writes first and then the events received from events, until
+// a verdict is sent, the client goes away or events is closed. A nil events
+// channel ends the stream after first, leaving the client to reconnect.
+func streamEvents(w http.ResponseWriter, r *http.Request, first queue.Event, events <-chan queue.Event) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	flusher := http.NewResponseController(w)
+	fmt.Fprintf(w, "retry: %d\n\n", eventRetryMS)
+	if err := writeEvent(w, first); err != nil || first.Kind == queue.EventVerdict || events == nil {
+		flusher.Flush()
+		return
+	}
+	if err := flusher.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+			if event.Kind == queue.EventVerdict {
+				flusher.Flush()
+				return
+			}
+		}
+		if err := flusher.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeEvent writes event in the text/event-stream format, with its JSON
+// form as the data.
+func writeEvent(w io.Writer, event queue.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error encoding event: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Kind, data)
+	return err
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"online-judge/internal/models"
+	"online-judge/internal/queue"
+)
+
+func TestStreamEvents(t *testing.T) {
+	pending := queue.Event{Kind: queue.EventStatus, SubmissionID: 4, Status: models.SubmissionPending}
+	verdict := queue.Event{
+		Kind: queue.EventVerdict, SubmissionID: 4, Status: models.SubmissionCompleted,
+		Result: models.ResultWrongAnswer, FailedTest: 2,
+	}
+
+	tests := []struct {
+		name   string
+		first  queue.Event
+		events []queue.Event
+		closed bool // events is closed after the queued ones
+		noFeed bool // no events channel at all
+		want   []string
+	}{
+		{
+			name:  "until the verdict",
+			first: pending,
+			events: []queue.Event{
+				{Kind: queue.EventStatus, SubmissionID: 4, Status: models.SubmissionProcessing, Total: 3},
+				{Kind: queue.EventProgress, SubmissionID: 4, Status: models.SubmissionProcessing, Passed: 1, Total: 3},
+				verdict,
+				{Kind: queue.EventStatus, SubmissionID: 4, Status: models.SubmissionPending},
+			},
+			want: []string{
+				"retry: 3000\n\n",
+				"event: status\ndata: {\"submission_id\":4,\"status\":\"pending\"}\n\n",
+				"event: status\ndata: {\"submission_id\":4,\"status\":\"processing\",\"total\":3}\n\n",
+				"event: progress\ndata: {\"submission_id\":4,\"status\":\"processing\",\"passed\":1,\"total\":3}\n\n",
+				"event: verdict\ndata: {\"submission_id\":4,\"status\":\"completed\",\"result\":\"wrong_answer\",\"failed_test\":2}\n\n",
+			},
+		},
+		{
+			name:  "already judged",
+			first: verdict,
+			events: []queue.Event{
+				{Kind: queue.EventStatus, SubmissionID: 4, Status: models.SubmissionPending},
+			},
+			want: []string{"retry: 3000\n\n", "event: verdict\n"},
+		},
+		{
+			name:   "server shutting down",
+			first:  pending,
+			closed: true,
+			want:   []string{"retry: 3000\n\n", "event: status\n"},
+		},
+		{
+			name:   "no event feed",
+			first:  pending,
+			noFeed: true,
+			want:   []string{"retry: 3000\n\n", "event: status\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events chan queue.Event
+			if !tt.noFeed {
+				events = make(chan queue.Event, len(tt.events))
+				for _, event := range tt.events {
+					events <- event
+				}
+				if tt.closed {
+					close(events)
+				}
+			}
+
+			recorder := httptest.NewRecorder()
+			streamEvents(recorder, httptest.NewRequest("GET", "/submissions/4/events", nil), tt.first, events)
+
+			if got := recorder.Header().Get("Content-Type"); got != "text/event-stream" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			body := recorder.Body.String()
+			if got := strings.Count(body, "event: "); got != len(tt.want)-1 {
+				t.Errorf("stream has %d events, want %d:\n%s", got, len(tt.want)-1, body)
+			}
+			rest := body
+			for _, want := range tt.want {
+				i := strings.Index(rest, want)
+				if i < 0 {
+					t.Fatalf("stream is missing %q in order:\n%s", want, body)
+				}
+				rest = rest[i+len(want):]
+			}
+		})
+	}
+}
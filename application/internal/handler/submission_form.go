@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"online-judge/internal/languages"
+	"online-judge/internal/models"
+)
+
+// maxSourceBytes bounds the size of submitted source code.
+const maxSourceBytes = 64 << 10
+
+// maxSubmissionFormBytes bounds the submission form's body. URL encoding can
+// triple the size of source code, so the body may be larger than the code.
+const maxSubmissionFormBytes = 3*maxSourceBytes + 4<<10
+
+// submissionCreatedResponse answers JSON clients that created a submission.
+type submissionCreatedResponse struct {
+	ID     int                     `json:"id"`
+	Status models.SubmissionStatus `json:"status"`
+	URL    string                  `json:"url"`
+}
+
+// parseSubmissionForm builds a pending submission from the question page's
+// form, or describes why the form cannot be accepted. The language must be
+// one of the enabled ones.
+func parseSubmissionForm(form url.Values, enabled *languages.Registry) (*models.Submission, string) {
+	submission := &models.Submission{
+		Code:     form.Get("code"),
+		Language: form.Get("language"),
+		Status:   models.SubmissionPending,
+	}
+
+	if _, ok := enabled.Get(submission.Language); !ok {
+		return submission, "Please choose a supported language"
+	}
+	if strings.TrimSpace(submission.Code) == "" {
+		return submission, "Your code is empty"
+	}
+	if len(submission.Code) > maxSourceBytes {
+		return submission, fmt.Sprintf("Your code must be at most %d KB", maxSourceBytes>>10)
+	}
+	return submission, ""
+}
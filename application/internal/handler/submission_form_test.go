@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"online-judge/internal/config"
+	"online-judge/internal/languages"
+	"online-judge/internal/models"
+)
+
+func TestParseSubmissionForm(t *testing.T) {
+	enabled, err := languages.NewRegistry(config.RunnerConfig{EnabledLanguages: []string{"go", "python3"}})
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		language    string
+		code        string
+		wantProblem string
+	}{
+		{name: "valid", language: "go", code: "package main\n"},
+		{name: "another enabled language", language: "python3", code: "print(1)\n"},
+		{name: "largest allowed", language: "go", code: strings.Repeat("a", maxSourceBytes)},
+		{name: "unknown language", language: "cobol", code: "package main", wantProblem: "supported language"},
+		{name: "language that is not enabled", language: "rust", code: "fn main() {}", wantProblem: "supported language"},
+		{name: "missing language", code: "package main", wantProblem: "supported language"},
+		{name: "blank code", language: "go", code: " \n\t", wantProblem: "empty"},
+		{name: "too large", language: "go", code: strings.Repeat("a", maxSourceBytes+1), wantProblem: "64 KB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{"language": {tt.language}, "code": {tt.code}}
+			submission, problem := parseSubmissionForm(form, enabled)
+			if tt.wantProblem == "" {
+				if problem != "" {
+					t.Fatalf("unexpected problem %q", problem)
+				}
+				if submission.Code != tt.code || submission.Language != tt.language || submission.Status != models.SubmissionPending {
+					t.Errorf("submission = %+v", submission)
+				}
+				return
+			}
+			if !strings.Contains(problem, tt.wantProblem) {
+				t.Errorf("problem = %q, want it to mention %q", problem, tt.wantProblem)
+			}
+		})
+	}
+}
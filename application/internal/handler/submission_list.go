@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"online-judge/internal/models"
+	"online-judge/internal/store"
+)
+
+// submissionsPerPage is the page size of the submission history.
+const submissionsPerPage = 20
+
+// submissionVerdicts are the values of the history's verdict filter: the
+// statuses of submissions not judged yet, and every result.
+var submissionVerdicts = map[string]bool{
+	string(models.SubmissionPending):         true,
+	string(models.SubmissionProcessing):      true,
+	string(models.ResultOK):                  true,
+	string(models.ResultCompileError):        true,
+	string(models.ResultWrongAnswer):         true,
+	string(models.ResultMemoryLimitExceeded): true,
+	string(models.ResultTimeLimitExceeded):   true,
+	string(models.ResultRuntimeError):        true,
+	string(models.ResultSystemError):         true,
+}
+
+// parseSubmissionQuery reads the submission history's query parameters for
+// the submissions of userID:
+//
+//	question  ID of the question submitted to
+//	verdict   a result such as wrong_answer, or pending or processing
+//	after     ID of the last submission of the previous page, from next links
+//	before    ID of the first submission of the next page, from previous links
+func parseSubmissionQuery(values url.Values, userID int) (store.SubmissionQuery, error) {
+	query := store.SubmissionQuery{
+		UserID:  userID,
+		Verdict: values.Get("verdict"),
+		Limit:   submissionsPerPage,
+	}
+
+	if query.Verdict != "" && !submissionVerdicts[query.Verdict] {
+		return query, fmt.Errorf("unknown verdict %q", query.Verdict)
+	}
+
+	var err error
+	if query.QuestionID, err = positiveParam(values, "question"); err != nil {
+		return query, err
+	}
+	if query.After, err = positiveParam(values, "after"); err != nil {
+		return query, err
+	}
+	if query.Before, err = positiveParam(values, "before"); err != nil {
+		return query, err
+	}
+	if query.After != 0 && query.Before != 0 {
+		return query, errors.New("after and before cannot be combined")
+	}
+	return query, nil
+}
+
+// positiveParam reads an optional ID parameter, returning 0 when it is absent.
+func positiveParam(values url.Values, name string) (int, error) {
+	value := values.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer", name)
+	}
+	return n, nil
+}
+
+// submissionPageCursors returns the cursors for the pages around page, empty
+// where there is no such page.
+func submissionPageCursors(page *store.SubmissionPage) (prev, next string) {
+	if len(page.Submissions) == 0 {
+		return "", ""
+	}
+	if page.HasPrev {
+		prev = strconv.Itoa(page.Submissions[0].ID)
+	}
+	if page.HasNext {
+		next = strconv.Itoa(page.Submissions[len(page.Submissions)-1].ID)
+	}
+	return prev, next
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"net/url"
+	"testing"
+
+	"online-judge/internal/store"
+)
+
+func TestParseSubmissionQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    store.SubmissionQuery
+		wantErr bool
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			want:  store.SubmissionQuery{UserID: 5, Limit: submissionsPerPage},
+		},
+		{
+			name:  "filters and cursor",
+			query: "question=12&verdict=time_limit_exceeded&after=40",
+			want: store.SubmissionQuery{
+				UserID: 5, QuestionID: 12, Verdict: "time_limit_exceeded", After: 40, Limit: submissionsPerPage,
+			},
+		},
+		{
+			name:  "not judged yet",
+			query: "verdict=pending&before=7",
+			want:  store.SubmissionQuery{UserID: 5, Verdict: "pending", Before: 7, Limit: submissionsPerPage},
+		},
+		{name: "unknown verdict", query: "verdict=accepted", wantErr: true},
+		{name: "bad question", query: "question=abc", wantErr: true},
+		{name: "negative cursor", query: "after=-3", wantErr: true},
+		{name: "both cursors", query: "after=3&before=9", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, _ := url.ParseQuery(tt.query)
+			got, err := parseSubmissionQuery(values, 5)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("query = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
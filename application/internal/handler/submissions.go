@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"online-judge/internal/middleware"
+	"online-judge/internal/models"
+	"online-judge/internal/store"
+)
+
+// submissionsHandler lists the current user's submissions, newest first.
+// Admins may list another user's submissions with ?user=<username>.
+func (h *Handler) submissionsHandler(w http.ResponseWriter, r *http.Request) {
+	user := middleware.UserFromContext(r.Context())
+
+	owner := user
+	if username := r.URL.Query().Get("user"); username != "" && username != user.Username {
+		if !user.IsAdmin() {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		var err error
+		owner, err = h.users.GetByUsername(r.Context(), username)
+		if errors.Is(err, store.ErrNotFound) {
+			http.Error(w, fmt.Sprintf("no user named %q", username), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	query, err := parseSubmissionQuery(r.URL.Query(), owner.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The question filter is shown by title, so it may only name questions
+	// the user can see
+	var question *models.Question
+	if query.QuestionID != 0 {
+		var ok bool
+		if question, ok = h.visibleQuestion(w, r, query.QuestionID); !ok {
+			return
+		}
+	}
+
+	page, err := h.submissions.List(r.Context(), query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	title := "My Submissions"
+	if owner.ID != user.ID {
+		title = "Submissions by " + owner.Username
+	}
+
+	prev, next := submissionPageCursors(page)
+	data := PageData{
+		Title:       title,
+		User:        user,
+		Question:    question,
+		Submissions: page.Submissions,
+		Query:       r.URL.Query(),
+		PrevPage:    pageURL("/submissions", r.URL.Query(), "before", prev),
+		NextPage:    pageURL("/submissions", r.URL.Query(), "after", next),
+	}
+
+	tmpl, err := template.ParseFiles(
+		"templates/base.html",
+		"templates/user-dashboard/submissions.html",
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// submissionHandler shows the code, verdict and test results of a submission
+// to its author and to admins. The output of hidden tests is only shown to
+// admins and the question's owner.
+func (h *Handler) submissionHandler(w http.ResponseWriter, r *http.Request) {
+	user := middleware.UserFromContext(r.Context())
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	submission, err := h.submissions.GetByID(r.Context(), id)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && !submission.VisibleTo(user)) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	testResults, err := h.submissions.ListTestResults(r.Context(), submission.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !user.IsAdmin() {
+		question, err := h.questions.GetByID(r.Context(), submission.QuestionID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if question.OwnerID != user.ID {
+			for i := range testResults {
+				testResults[i].Redact()
+			}
+		}
+	}
+
+	data := PageData{
+		Title:       fmt.Sprintf("Submission %d", submission.ID),
+		User:        user,
+		Submissions: []models.Submission{*submission},
+		TestResults: testResults,
+	}
+
+	tmpl, err := template.ParseFiles(
+		"templates/base.html",
+		"templates/user-dashboard/submission.html",
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
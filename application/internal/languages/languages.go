@@ -0,0 +1,243 @@
+// Package languages is the registry of programming languages submissions can
+// be written in, and of how runners build and run each of them.
+package languages
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"online-judge/internal/config"
+)
+
+// maxIDLength matches the submissions table's language column.
+const maxIDLength = 20
+
+// Language describes how to judge programs written in one language. Commands
+// run in the directory holding the source file; a Run command starting with
+// "./" executes a file produced by Compile.
+type Language struct {
+	ID   string // form value, stored with each submission
+	Name string // shown to users
+	// SourceFile is the name the submitted code is saved under.
+	SourceFile string
+	// Compile builds the source; it is empty for languages that are only
+	// interpreted. A failing Compile is reported as a compile error.
+	Compile []string
+	// CompileEnv is added to the compiler's environment in the sandbox.
+	CompileEnv []string
+	// Version prints the compiler's version. Builds are only cached for
+	// languages that have it, so that a toolchain upgrade invalidates them.
+	Version []string
+	Run     []string
+	// TimeMultiplier scales a question's time limit, and MemoryOverheadMB
+	// is added to its memory limit, to make up for slower runtimes and for
+	// the memory of interpreters and virtual machines.
+	TimeMultiplier   float64
+	MemoryOverheadMB int
+}
+
+// builtins are the languages known without any configuration.
+var builtins = []Language{
+	{
+		ID:         "go",
+		Name:       "Go",
+		SourceFile: "main.go",
+		Compile:    []string{"go", "build", "-o", "main", "main.go"},
+		// Submissions may only use the standard library and must never
+		// trigger toolchain or module downloads on the judging host.
+		CompileEnv:     []string{"CGO_ENABLED=0", "GOTOOLCHAIN=local", "GOPROXY=off", "GOFLAGS="},
+		Version:        []string{"go", "version"},
+		Run:            []string{"./main"},
+		TimeMultiplier: 1,
+	},
+	{
+		ID:             "c",
+		Name:           "C",
+		SourceFile:     "main.c",
+		Compile:        []string{"gcc", "-std=c17", "-O2", "-o", "main", "main.c", "-lm"},
+		Version:        []string{"gcc", "--version"},
+		Run:            []string{"./main"},
+		TimeMultiplier: 1,
+	},
+	{
+		ID:             "cpp",
+		Name:           "C++",
+		SourceFile:     "main.cpp",
+		Compile:        []string{"g++", "-std=c++17", "-O2", "-o", "main", "main.cpp"},
+		Version:        []string{"g++", "--version"},
+		Run:            []string{"./main"},
+		TimeMultiplier: 1,
+	},
+	{
+		ID:         "python3",
+		Name:       "Python 3",
+		SourceFile: "main.py",
+		// Checking the syntax up front reports it as a compile error
+		Compile:          []string{"python3", "-m", "py_compile", "main.py"},
+		Version:          []string{"python3", "--version"},
+		Run:              []string{"python3", "main.py"},
+		TimeMultiplier:   3,
+		MemoryOverheadMB: 16,
+	},
+	{
+		ID:               "java",
+		Name:             "Java",
+		SourceFile:       "Main.java",
+		Compile:          []string{"javac", "-encoding", "UTF-8", "Main.java"},
+		Version:          []string{"javac", "-version"},
+		Run:              []string{"java", "-XX:+UseSerialGC", "-Xss64m", "Main"},
+		TimeMultiplier:   2,
+		MemoryOverheadMB: 64,
+	},
+	{
+		ID:             "rust",
+		Name:           "Rust",
+		SourceFile:     "main.rs",
+		Compile:        []string{"rustc", "--edition=2021", "-O", "-o", "main", "main.rs"},
+		Version:        []string{"rustc", "--version"},
+		Run:            []string{"./main"},
+		TimeMultiplier: 1,
+	},
+}
+
+// Registry holds the enabled languages.
+type Registry struct {
+	languages []Language
+	byID      map[string]int
+}
+
+// NewRegistry builds the registry of the languages enabled in cfg. Entries of
+// cfg.Languages override the fields they set of the built-in language with
+// the same ID, or define new languages.
+func NewRegistry(cfg config.RunnerConfig) (*Registry, error) {
+	known := make(map[string]Language, len(builtins)+len(cfg.Languages))
+	for _, language := range builtins {
+		known[language.ID] = language
+	}
+	for id, override := range cfg.Languages {
+		language, err := merge(known[id], id, override)
+		if err != nil {
+			return nil, err
+		}
+		known[id] = language
+	}
+
+	registry := &Registry{byID: make(map[string]int)}
+	for _, id := range cfg.EnabledLanguages {
+		language, ok := known[id]
+		if !ok {
+			return nil, fmt.Errorf("enabled language %q is not defined", id)
+		}
+		if _, ok := registry.byID[id]; ok {
+			return nil, fmt.Errorf("language %q is enabled twice", id)
+		}
+		registry.byID[id] = len(registry.languages)
+		registry.languages = append(registry.languages, language)
+	}
+	if len(registry.languages) == 0 {
+		return nil, errors.New("no language is enabled")
+	}
+	return registry, nil
+}
+
+// merge applies the fields set in override to language and validates the
+// result.
+func merge(language Language, id string, override config.LanguageConfig) (Language, error) {
+	language.ID = id
+	if override.Name != "" {
+		language.Name = override.Name
+	}
+	if override.SourceFile != "" {
+		language.SourceFile = override.SourceFile
+	}
+	if override.Compile != nil {
+		language.Compile = override.Compile
+	}
+	if override.CompileEnv != nil {
+		language.CompileEnv = override.CompileEnv
+	}
+	if override.Version != nil {
+		language.Version = override.Version
+	}
+	if override.Run != nil {
+		language.Run = override.Run
+	}
+	if override.TimeMultiplier != 0 {
+		language.TimeMultiplier = override.TimeMultiplier
+	}
+	if override.MemoryOverheadMB != 0 {
+		language.MemoryOverheadMB = override.MemoryOverheadMB
+	}
+
+	switch {
+	case len(id) > maxIDLength || strings.Trim(id, "abcdefghijklmnopqrstuvwxyz0123456789_") != "":
+		return language, fmt.Errorf("language ID %q must be at most %d lower-case letters, digits or underscores", id, maxIDLength)
+	case language.Name == "":
+		return language, fmt.Errorf("language %q needs a name", id)
+	case language.SourceFile == "" || strings.ContainsAny(language.SourceFile, `/\`):
+		return language, fmt.Errorf("language %q needs a source file name without directories", id)
+	case len(language.Run) == 0:
+		return language, fmt.Errorf("language %q needs a run command", id)
+	case language.TimeMultiplier <= 0:
+		return language, fmt.Errorf("language %q needs a positive time multiplier", id)
+	case language.MemoryOverheadMB < 0:
+		return language, fmt.Errorf("language %q cannot have a negative memory overhead", id)
+	}
+	return language, nil
+}
+
+// All returns the enabled languages in the order they were enabled.
+func (r *Registry) All() []Language {
+	return r.languages
+}
+
+// Get returns the enabled language with the given ID.
+func (r *Registry) Get(id string) (Language, bool) {
+	i, ok := r.byID[id]
+	if !ok {
+		return Language{}, false
+	}
+	return r.languages[i], true
+}
+
+// Installed returns a copy of the language whose commands name the programs
+// found on this host, or an error if one of them is missing. Compile and
+// Version are looked up in compilePath, the PATH compilers get, and Run in
+// runPath, the PATH programs get inside the sandbox.
+func (l Language) Installed(compilePath, runPath string) (Language, error) {
+	var err error
+	if len(l.Compile) > 0 {
+		if l.Compile, err = resolve(l.Compile, compilePath); err != nil {
+			return l, err
+		}
+	}
+	if len(l.Version) > 0 {
+		if l.Version, err = resolve(l.Version, compilePath); err != nil {
+			return l, err
+		}
+	}
+	if l.Run, err = resolve(l.Run, runPath); err != nil {
+		return l, err
+	}
+	return l, nil
+}
+
+// resolve looks up the program of a command in the directories of path.
+// Programs given with a directory, such as "./main" produced by compilation,
+// are left alone.
+func resolve(command []string, path string) ([]string, error) {
+	if strings.Contains(command[0], "/") {
+		return command, nil
+	}
+	for _, dir := range filepath.SplitList(path) {
+		candidate := filepath.Join(dir, command[0])
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() && info.Mode()&0o111 != 0 {
+			return append([]string{candidate}, command[1:]...), nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %w", command[0], exec.ErrNotFound)
+}
@@ -0,0 +1,186 @@
+package languages
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"online-judge/internal/config"
+)
+
+func TestNewRegistry(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.RunnerConfig
+		wantIDs []string
+		wantErr bool
+	}{
+		{
+			name:    "built-ins in the enabled order",
+			cfg:     config.RunnerConfig{EnabledLanguages: []string{"python3", "go"}},
+			wantIDs: []string{"python3", "go"},
+		},
+		{
+			name: "new language",
+			cfg: config.RunnerConfig{
+				EnabledLanguages: []string{"go", "pypy3"},
+				Languages: map[string]config.LanguageConfig{
+					"pypy3": {Name: "PyPy 3", SourceFile: "main.py", Run: []string{"pypy3", "main.py"}, TimeMultiplier: 1.5},
+				},
+			},
+			wantIDs: []string{"go", "pypy3"},
+		},
+		{
+			name: "disabled definitions are still validated",
+			cfg: config.RunnerConfig{
+				EnabledLanguages: []string{"go"},
+				Languages:        map[string]config.LanguageConfig{"broken": {Name: "Broken"}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "unknown language",
+			cfg:     config.RunnerConfig{EnabledLanguages: []string{"go", "cobol"}},
+			wantErr: true,
+		},
+		{
+			name:    "language enabled twice",
+			cfg:     config.RunnerConfig{EnabledLanguages: []string{"go", "c", "go"}},
+			wantErr: true,
+		},
+		{
+			name:    "nothing enabled",
+			cfg:     config.RunnerConfig{},
+			wantErr: true,
+		},
+		{
+			name: "invalid ID",
+			cfg: config.RunnerConfig{
+				EnabledLanguages: []string{"C#"},
+				Languages: map[string]config.LanguageConfig{
+					"C#": {Name: "C#", SourceFile: "Main.cs", Run: []string{"dotnet", "run"}, TimeMultiplier: 1},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "source file in a directory",
+			cfg: config.RunnerConfig{
+				EnabledLanguages: []string{"c"},
+				Languages:        map[string]config.LanguageConfig{"c": {SourceFile: "../main.c"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "new language without a time multiplier",
+			cfg: config.RunnerConfig{
+				EnabledLanguages: []string{"ruby"},
+				Languages: map[string]config.LanguageConfig{
+					"ruby": {Name: "Ruby", SourceFile: "main.rb", Run: []string{"ruby", "main.rb"}},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry, err := NewRegistry(tt.cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("NewRegistry succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewRegistry: %v", err)
+			}
+			var ids []string
+			for _, language := range registry.All() {
+				ids = append(ids, language.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("enabled %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestNewRegistryOverridesBuiltins(t *testing.T) {
+	registry, err := NewRegistry(config.RunnerConfig{
+		EnabledLanguages: []string{"cpp"},
+		Languages: map[string]config.LanguageConfig{
+			"cpp": {Compile: []string{"g++", "-std=c++20", "-O2", "-o", "main", "main.cpp"}, MemoryOverheadMB: 8},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+
+	cpp, ok := registry.Get("cpp")
+	if !ok {
+		t.Fatal("cpp is not enabled")
+	}
+	if cpp.Compile[1] != "-std=c++20" || cpp.MemoryOverheadMB != 8 {
+		t.Errorf("overrides not applied: %+v", cpp)
+	}
+	if cpp.Name != "C++" || cpp.SourceFile != "main.cpp" || cpp.TimeMultiplier != 1 {
+		t.Errorf("unset fields not kept from the built-in: %+v", cpp)
+	}
+	if _, ok := registry.Get("go"); ok {
+		t.Error("Get found a language that is not enabled")
+	}
+}
+
+func TestInstalled(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "interpreter"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes"), []byte("not a program"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		run     []string
+		want    []string
+		wantErr bool
+	}{
+		{"found in the run path", []string{"interpreter", "main.x"}, []string{filepath.Join(dir, "interpreter"), "main.x"}, false},
+		{"compiled program", []string{"./main"}, []string{"./main"}, false},
+		{"missing", []string{"absent", "main.x"}, nil, true},
+		{"not executable", []string{"notes"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			language, err := Language{Run: tt.run}.Installed("", dir)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Installed succeeded with %v", language.Run)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Installed: %v", err)
+			}
+			if !reflect.DeepEqual(language.Run, tt.want) {
+				t.Errorf("Run = %v, want %v", language.Run, tt.want)
+			}
+		})
+	}
+
+	compiled := Language{Compile: []string{"interpreter", "main.x"}, Run: []string{"./main"}}
+	if _, err := compiled.Installed("", dir); err == nil {
+		t.Error("Installed found the compiler outside the compile path")
+	}
+	language, err := compiled.Installed(dir, "")
+	if err != nil {
+		t.Fatalf("Installed: %v", err)
+	}
+	if want := []string{filepath.Join(dir, "interpreter"), "main.x"}; !reflect.DeepEqual(language.Compile, want) {
+		t.Errorf("Compile = %v, want %v", language.Compile, want)
+	}
+}
@@ -0,0 +1,223 @@
+// Package markdown renders question statements from Markdown to HTML.
+//
+// Statements may contain TeX math between $...$ (inline) or $$...$$
+// (display). Math is passed through untouched, wrapped in \(...\) and
+// \[...\] delimiters for a client-side renderer such as KaTeX, so that
+// Markdown never mistakes subscripts or products for emphasis.
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"html/template"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// Raw HTML in statements is dropped, not rendered: goldmark escapes it
+// unless told otherwise, and filters javascript: and similar link targets.
+var renderer = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// Math spans are swapped for placeholders made of private-use runes before
+// the Markdown is rendered, and swapped back afterwards.
+const (
+	placeholderStart = '\uE000'
+	placeholderEnd   = '\uE001'
+)
+
+// Render converts a Markdown statement to sanitized HTML.
+func Render(source string) (template.HTML, error) {
+	text, math := extractMath(source)
+
+	var out bytes.Buffer
+	if err := renderer.Convert([]byte(text), &out); err != nil {
+		return "", fmt.Errorf("error rendering markdown: %w", err)
+	}
+	return template.HTML(restoreMath(out.String(), math)), nil
+}
+
+// mathSpan is a piece of TeX taken out of the source.
+type mathSpan struct {
+	tex     string
+	display bool
+}
+
+// extractMath replaces the math spans of source with placeholders. Dollar
+// signs inside code spans and fenced code blocks, or escaped as \$, are
+// left alone, as are amounts of money such as "$5 or $10".
+func extractMath(source string) (string, []mathSpan) {
+	source = strings.Map(func(r rune) rune {
+		if r == placeholderStart || r == placeholderEnd {
+			return -1
+		}
+		return r
+	}, source)
+
+	var out strings.Builder
+	var spans []mathSpan
+	var text strings.Builder
+	flush := func() {
+		out.WriteString(extractMathFromText(text.String(), &spans))
+		text.Reset()
+	}
+
+	// Fenced code blocks are copied verbatim; everything else is scanned
+	fence := ""
+	for _, line := range strings.SplitAfter(source, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		switch {
+		case fence != "":
+			out.WriteString(line)
+			if strings.HasPrefix(trimmed, fence) && strings.TrimSpace(strings.TrimLeft(trimmed, fence[:1])) == "" {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			flush()
+			fence = trimmed[:3]
+			out.WriteString(line)
+		default:
+			text.WriteString(line)
+		}
+	}
+	flush()
+	return out.String(), spans
+}
+
+// extractMathFromText handles a run of source outside fenced code blocks.
+func extractMathFromText(text string, spans *[]mathSpan) string {
+	var out strings.Builder
+	placeholder := func(tex string, display bool) {
+		*spans = append(*spans, mathSpan{tex: tex, display: display})
+		out.WriteRune(placeholderStart)
+		out.WriteString(strconv.Itoa(len(*spans) - 1))
+		out.WriteRune(placeholderEnd)
+	}
+
+	for i := 0; i < len(text); {
+		switch {
+		case text[i] == '\\' && i+1 < len(text):
+			out.WriteString(text[i : i+2])
+			i += 2
+
+		case text[i] == '`':
+			// A code span ends at the next run of the same number of backticks
+			run := len(text[i:]) - len(strings.TrimLeft(text[i:], "`"))
+			end := closingBackticks(text[i+run:], run)
+			if end < 0 {
+				out.WriteString(text[i : i+run])
+				i += run
+				continue
+			}
+			end += i + 2*run
+			out.WriteString(text[i:end])
+			i = end
+
+		case strings.HasPrefix(text[i:], "$$"):
+			end := strings.Index(text[i+2:], "$$")
+			if end < 0 || strings.TrimSpace(text[i+2:i+2+end]) == "" {
+				out.WriteString("$$")
+				i += 2
+				continue
+			}
+			placeholder(strings.TrimSpace(text[i+2:i+2+end]), true)
+			i += end + 4
+
+		case text[i] == '$':
+			end := closingDollar(text[i+1:])
+			if end < 0 {
+				out.WriteByte('$')
+				i++
+				continue
+			}
+			placeholder(text[i+1:i+1+end], false)
+			i += end + 2
+
+		default:
+			out.WriteByte(text[i])
+			i++
+		}
+	}
+	return out.String()
+}
+
+// closingBackticks returns the index in s of a run of exactly n backticks,
+// or -1.
+func closingBackticks(s string, n int) int {
+	for i := 0; i < len(s); {
+		if s[i] != '`' {
+			i++
+			continue
+		}
+		run := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+		if run == n {
+			return i
+		}
+		i += run
+	}
+	return -1
+}
+
+// closingDollar returns the index in s of the dollar sign closing inline math
+// that starts just before s, or -1 if s does not start inline math. As in
+// Pandoc, the math must not begin or end with a space and the closing dollar
+// must not be followed by a digit; it cannot span lines.
+func closingDollar(s string) int {
+	if s == "" || s[0] == ' ' || s[0] == '\n' || s[0] == '\t' {
+		return -1
+	}
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\n':
+			return -1
+		case '\\':
+			i++
+		case '$':
+			if i == 0 || s[i-1] == ' ' || s[i-1] == '\t' {
+				continue
+			}
+			if i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9' {
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// restoreMath swaps the placeholders in rendered HTML for escaped TeX.
+func restoreMath(rendered string, spans []mathSpan) string {
+	if len(spans) == 0 {
+		return rendered
+	}
+
+	var out strings.Builder
+	for {
+		start := strings.IndexRune(rendered, placeholderStart)
+		if start < 0 {
+			break
+		}
+		end := strings.IndexRune(rendered[start:], placeholderEnd)
+		if end < 0 {
+			break
+		}
+		end += start
+
+		out.WriteString(rendered[:start])
+		n, err := strconv.Atoi(rendered[start+len(string(placeholderStart)) : end])
+		if err == nil && n < len(spans) {
+			span := spans[n]
+			if span.display {
+				out.WriteString(`<span class="math display">\[` + html.EscapeString(span.tex) + `\]</span>`)
+			} else {
+				out.WriteString(`<span class="math inline">\(` + html.EscapeString(span.tex) + `\)</span>`)
+			}
+		}
+		rendered = rendered[end+len(string(placeholderEnd)):]
+	}
+	out.WriteString(rendered)
+	return out.String()
+}
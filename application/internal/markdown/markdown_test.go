@@ -0,0 +1,80 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "markdown",
+			source:   "# Input\n\nTwo **integers** per line.",
+			contains: []string{"<h1>Input</h1>", "<strong>integers</strong>"},
+		},
+		{
+			name:     "inline math is not emphasis",
+			source:   "Compute $a_1 * b_2 * c_3$ for each $i$.",
+			contains: []string{`<span class="math inline">\(a_1 * b_2 * c_3\)</span>`, `\(i\)`},
+			excludes: []string{"<em>"},
+		},
+		{
+			name:     "display math across lines",
+			source:   "Sum:\n\n$$\n\\sum_{i=1}^{n} a_i < 10^9\n$$\n",
+			contains: []string{`<span class="math display">\[\sum_{i=1}^{n} a_i &lt; 10^9\]</span>`},
+		},
+		{
+			name:     "money is not math",
+			source:   "It costs $5 or $10.",
+			contains: []string{"It costs $5 or $10."},
+			excludes: []string{"math"},
+		},
+		{
+			name:     "escaped dollar",
+			source:   `Print \$x\$ literally.`,
+			contains: []string{"Print $x$ literally."},
+			excludes: []string{"math"},
+		},
+		{
+			name:     "code span keeps dollars",
+			source:   "Run `echo $HOME $PATH` first.",
+			contains: []string{"<code>echo $HOME $PATH</code>"},
+			excludes: []string{"math"},
+		},
+		{
+			name:     "fenced code keeps dollars",
+			source:   "```\nx = $a$\n```\n\nThen $b$.",
+			contains: []string{"x = $a$", `\(b\)`},
+		},
+		{
+			name:     "raw html is dropped",
+			source:   "Hello <script>alert(1)</script> [x](javascript:alert(1))",
+			excludes: []string{"<script>", "javascript:"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := Render(tt.source)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			html := string(rendered)
+			for _, want := range tt.contains {
+				if !strings.Contains(html, want) {
+					t.Errorf("output does not contain %q:\n%s", want, html)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(html, unwanted) {
+					t.Errorf("output contains %q:\n%s", unwanted, html)
+				}
+			}
+		})
+	}
+}
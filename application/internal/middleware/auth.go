@@ -0,0 +1,108 @@
+// Package middleware provides HTTP middleware for the web server.
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"online-judge/internal/models"
+)
+
+type contextKey int
+
+const userKey contextKey = iota
+
+// UserResolver looks up the signed-in user of a request. It returns a nil
+// user without an error for anonymous requests.
+type UserResolver func(r *http.Request) (*models.User, error)
+
+// LoadUser resolves the current user once per request and stores it in the
+// request context for UserFromContext and the Require* middleware.
+func LoadUser(resolve UserResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, err := resolve(r)
+			if err != nil {
+				log.Printf("Error resolving current user: %v", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+			if user != nil {
+				r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// UserFromContext returns the user stored by LoadUser, or nil for anonymous requests.
+func UserFromContext(ctx context.Context) *models.User {
+	user, _ := ctx.Value(userKey).(*models.User)
+	return user
+}
+
+// RequireLogin rejects anonymous requests: API clients get 401, browsers are
+// redirected to the login page.
+func RequireLogin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if UserFromContext(r.Context()) == nil {
+			unauthorized(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireRole rejects requests whose user does not have role: API clients get
+// 401 or 403, browsers are redirected to the login page or the dashboard.
+func RequireRole(role models.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := UserFromContext(r.Context())
+			if user == nil {
+				unauthorized(w, r)
+				return
+			}
+			if user.Role != role {
+				forbidden(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request) {
+	if WantsJSON(r) {
+		WriteJSONError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	http.Redirect(w, r, "/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+}
+
+func forbidden(w http.ResponseWriter, r *http.Request) {
+	if WantsJSON(r) {
+		WriteJSONError(w, http.StatusForbidden, "insufficient permissions")
+		return
+	}
+	http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
+}
+
+// WantsJSON reports whether the request comes from an API client rather than a browser page.
+func WantsJSON(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/api/") ||
+		strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+// WriteJSONError writes a JSON body of the form {"error": message}.
+func WriteJSONError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"error": message}); err != nil {
+		log.Printf("Error writing JSON error: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"online-judge/internal/models"
+)
+
+func TestAuthorization(t *testing.T) {
+	regular := &models.User{ID: 1, Username: "user1", Role: models.RoleRegular}
+	admin := &models.User{ID: 2, Username: "admin", Role: models.RoleAdmin}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name         string
+		user         *models.User
+		guard        func(http.Handler) http.Handler
+		accept       string
+		wantStatus   int
+		wantLocation string
+	}{
+		{"anonymous page", nil, RequireLogin, "text/html", http.StatusSeeOther, "/login?next=%2Fpage"},
+		{"anonymous api", nil, RequireLogin, "application/json", http.StatusUnauthorized, ""},
+		{"signed in", regular, RequireLogin, "text/html", http.StatusOK, ""},
+		{"regular on admin page", regular, RequireRole(models.RoleAdmin), "text/html", http.StatusSeeOther, "/dashboard"},
+		{"regular on admin api", regular, RequireRole(models.RoleAdmin), "application/json", http.StatusForbidden, ""},
+		{"anonymous on admin api", nil, RequireRole(models.RoleAdmin), "application/json", http.StatusUnauthorized, ""},
+		{"admin on admin page", admin, RequireRole(models.RoleAdmin), "text/html", http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolve := func(*http.Request) (*models.User, error) { return tt.user, nil }
+			handler := LoadUser(resolve)(tt.guard(ok))
+
+			req := httptest.NewRequest("GET", "/page", nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+		})
+	}
+}
+
+func TestLoadUserFailsOnResolverError(t *testing.T) {
+	resolve := func(*http.Request) (*models.User, error) { return nil, errors.New("db down") }
+	handler := LoadUser(resolve)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("next handler called despite resolver error")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
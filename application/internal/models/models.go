@@ -0,0 +1,382 @@
+// Package models defines the domain types shared between the web server,
+// the storage layer and the code runner.
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Role is the access level of a user account.
+type Role string
+
+const (
+	RoleRegular Role = "regular"
+	RoleAdmin   Role = "admin"
+)
+
+// QuestionStatus is the publication state of a question.
+type QuestionStatus string
+
+const (
+	QuestionDraft     QuestionStatus = "draft"
+	QuestionReview    QuestionStatus = "review"
+	QuestionPublished QuestionStatus = "published"
+)
+
+// CheckerType selects how the output of a program is compared with the
+// expected output of a test case.
+type CheckerType string
+
+const (
+	// CheckerExact accepts only byte-for-byte identical output.
+	CheckerExact CheckerType = "exact"
+	// CheckerWhitespace ignores trailing whitespace on each line and
+	// trailing blank lines.
+	CheckerWhitespace CheckerType = "whitespace"
+	// CheckerTokens compares the whitespace-separated tokens.
+	CheckerTokens CheckerType = "tokens"
+	// CheckerFloat compares tokens, accepting numbers within an absolute or
+	// relative error of the checker's epsilon.
+	CheckerFloat CheckerType = "float"
+	// CheckerCaseInsensitive is CheckerWhitespace ignoring letter case.
+	CheckerCaseInsensitive CheckerType = "case_insensitive"
+	// CheckerUnorderedLines accepts the expected lines in any order.
+	CheckerUnorderedLines CheckerType = "unordered_lines"
+	// CheckerCustom runs a checker program supplied with the question.
+	CheckerCustom CheckerType = "custom"
+)
+
+// CheckerTypes lists the checkers in the order the question editor offers
+// them.
+var CheckerTypes = []CheckerType{
+	CheckerWhitespace, CheckerExact, CheckerTokens, CheckerFloat,
+	CheckerCaseInsensitive, CheckerUnorderedLines, CheckerCustom,
+}
+
+// Label is the checker's name as shown to users.
+func (t CheckerType) Label() string {
+	switch t {
+	case CheckerExact:
+		return "Exact match"
+	case CheckerWhitespace:
+		return "Ignore trailing whitespace"
+	case CheckerTokens:
+		return "Tokens"
+	case CheckerFloat:
+		return "Floating-point tokens"
+	case CheckerCaseInsensitive:
+		return "Case-insensitive"
+	case CheckerUnorderedLines:
+		return "Lines in any order"
+	case CheckerCustom:
+		return "Custom checker"
+	}
+	return string(t)
+}
+
+// Checker is how the output of a question's submissions is judged.
+type Checker struct {
+	Type CheckerType `json:"type"`
+	// Epsilon is the largest absolute or relative error CheckerFloat
+	// accepts.
+	Epsilon float64 `json:"epsilon,omitempty"`
+	// Language and Source are the program of a CheckerCustom.
+	Language string `json:"language,omitempty"`
+	Source   string `json:"source,omitempty"`
+}
+
+// Interactor is the program that judges an interactive question. It runs
+// alongside each submission with its stdout connected to the submission's
+// stdin and the other way round, and its exit code decides the verdict.
+type Interactor struct {
+	Language string `json:"language"`
+	Source   string `json:"source"`
+}
+
+// SubmissionStatus is the judging state of a submission.
+type SubmissionStatus string
+
+const (
+	SubmissionPending    SubmissionStatus = "pending"
+	SubmissionProcessing SubmissionStatus = "processing"
+	SubmissionCompleted  SubmissionStatus = "completed"
+)
+
+// SubmissionResult is the final verdict of a judged submission.
+type SubmissionResult string
+
+const (
+	ResultOK                  SubmissionResult = "ok"
+	ResultCompileError        SubmissionResult = "compile_error"
+	ResultWrongAnswer         SubmissionResult = "wrong_answer"
+	ResultMemoryLimitExceeded SubmissionResult = "memory_limit_exceeded"
+	ResultTimeLimitExceeded   SubmissionResult = "time_limit_exceeded"
+	ResultRuntimeError        SubmissionResult = "runtime_error"
+	ResultSystemError         SubmissionResult = "system_error"
+)
+
+// User is a registered account.
+type User struct {
+	ID           int        `db:"id"`
+	Username     string     `db:"username"`
+	Email        string     `db:"email"`
+	PasswordHash string     `db:"password_hash"`
+	FullName     string     `db:"full_name"`
+	Role         Role       `db:"role"`
+	DisabledAt   *time.Time `db:"disabled_at"` // set while an admin has disabled the account
+	CreatedAt    time.Time  `db:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at"`
+}
+
+// IsAdmin reports whether the user has the admin role.
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// IsDisabled reports whether the account is disabled, which keeps the user
+// from signing in.
+func (u *User) IsDisabled() bool {
+	return u.DisabledAt != nil
+}
+
+// UserAuditAction is a kind of change an admin made to a user account.
+type UserAuditAction string
+
+const (
+	AuditRoleChanged UserAuditAction = "role_changed"
+	AuditDisabled    UserAuditAction = "disabled"
+	AuditEnabled     UserAuditAction = "enabled"
+)
+
+// UserAuditEntry records a change an admin made to a user account.
+type UserAuditEntry struct {
+	ID int `db:"id"`
+	// ActorID is nil for changes made from the command line and once the
+	// admin's account is deleted; ActorUsername is empty then.
+	ActorID        *int            `db:"actor_id"`
+	ActorUsername  string          `db:"actor_username"`
+	TargetID       int             `db:"target_id"`
+	TargetUsername string          `db:"target_username"`
+	Action         UserAuditAction `db:"action"`
+	// OldValue and NewValue are the roles before and after a role change.
+	OldValue  string    `db:"old_value"`
+	NewValue  string    `db:"new_value"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// MaxMemoryLimitMB is the highest memory limit a question may set; runners
+// must not cap memory below it.
+const MaxMemoryLimitMB = 1024
+
+// MaxTestCases is the most test cases a question may have.
+const MaxTestCases = 100
+
+// MaxExcerptBytes caps each output excerpt and message kept in a test result,
+// and MaxMessageBytes the error message of a verdict.
+const (
+	MaxExcerptBytes = 1 << 10
+	MaxMessageBytes = 64 << 10
+)
+
+// Question is a programming problem together with its judging limits.
+type Question struct {
+	ID            int            `db:"id"`
+	Title         string         `db:"title"`
+	Statement     string         `db:"statement"`
+	Difficulty    string         `db:"difficulty"` // "easy", "medium", "hard"
+	Tags          pq.StringArray `db:"tags"`
+	TimeLimitMS   int            `db:"time_limit_ms"`
+	MemoryLimitMB int            `db:"memory_limit_mb"`
+	Status        QuestionStatus `db:"status"`
+	OwnerID       int            `db:"owner_id"`
+	OwnerUsername string         `db:"owner_username"`
+	PublishedAt   *time.Time     `db:"published_at"`
+	ReviewComment string         `db:"review_comment"`
+	// CheckerType and the other checker fields make up Checker().
+	CheckerType     CheckerType `db:"checker"`
+	CheckerEpsilon  float64     `db:"checker_epsilon"`
+	CheckerLanguage string      `db:"checker_language"`
+	CheckerSource   string      `db:"checker_source"`
+	// Interactive questions are judged by the interactor program instead of
+	// the checker.
+	Interactive        bool       `db:"interactive"`
+	InteractorLanguage string     `db:"interactor_language"`
+	InteractorSource   string     `db:"interactor_source"`
+	Solved             bool       `db:"solved"` // by the user a list was made for; only set by list queries
+	CreatedAt          time.Time  `db:"created_at"`
+	UpdatedAt          time.Time  `db:"updated_at"`
+	TestCases          []TestCase `db:"-"`
+}
+
+// VisibleTo reports whether user may see the question. Published questions
+// are visible to everyone; drafts and questions in review only to their
+// owner and to admins.
+func (q *Question) VisibleTo(user *User) bool {
+	if q.Status == QuestionPublished {
+		return true
+	}
+	return user != nil && (user.ID == q.OwnerID || user.IsAdmin())
+}
+
+// Checker returns how the output of the question's submissions is judged.
+func (q *Question) Checker() Checker {
+	return Checker{
+		Type:     q.CheckerType,
+		Epsilon:  q.CheckerEpsilon,
+		Language: q.CheckerLanguage,
+		Source:   q.CheckerSource,
+	}
+}
+
+// Interactor returns the interactor of an interactive question, or nil.
+func (q *Question) Interactor() *Interactor {
+	if !q.Interactive {
+		return nil
+	}
+	return &Interactor{Language: q.InteractorLanguage, Source: q.InteractorSource}
+}
+
+// CanEdit reports whether user may change the question, which only its owner
+// can do, and only while it is a draft.
+func (q *Question) CanEdit(user *User) bool {
+	return user != nil && user.ID == q.OwnerID && q.Status == QuestionDraft
+}
+
+// TestCase is a single input/expected-output pair of a question.
+type TestCase struct {
+	ID             int    `db:"id"`
+	QuestionID     int    `db:"question_id"`
+	Input          string `db:"input"`
+	ExpectedOutput string `db:"expected_output"`
+	IsSample       bool   `db:"is_sample"`
+}
+
+// Submission is a piece of code submitted by a user for a question.
+type Submission struct {
+	ID              int               `db:"id"`
+	UserID          int               `db:"user_id"`
+	QuestionID      int               `db:"question_id"`
+	QuestionTitle   string            `db:"question_title"`
+	Username        string            `db:"username"`
+	Code            string            `db:"code"`
+	Language        string            `db:"language"`
+	Status          SubmissionStatus  `db:"status"`
+	Result          *SubmissionResult `db:"result"`
+	ErrorMessage    *string           `db:"error_message"`
+	ExecutionTimeMS *int              `db:"execution_time_ms"`
+	MemoryUsageMB   *int              `db:"memory_usage_mb"`
+	FailedTest      *int              `db:"failed_test"`
+	WorkerID        *string           `db:"worker_id"`
+	LeaseExpiresAt  *time.Time        `db:"lease_expires_at"`
+	Attempts        int               `db:"attempts"`
+	CreatedAt       time.Time         `db:"created_at"`
+	UpdatedAt       time.Time         `db:"updated_at"`
+}
+
+// VisibleTo reports whether user may see the submission and its code, which
+// only its author and admins can.
+func (s *Submission) VisibleTo(user *User) bool {
+	return user != nil && (user.ID == s.UserID || user.IsAdmin())
+}
+
+// Outcome is the submission's result once it is judged, and its status
+// until then.
+func (s *Submission) Outcome() string {
+	if s.Status == SubmissionCompleted && s.Result != nil {
+		return string(*s.Result)
+	}
+	return string(s.Status)
+}
+
+// Verdict is the outcome of judging a submission, as reported by a runner.
+type Verdict struct {
+	Result          SubmissionResult `json:"result"`
+	ExecutionTimeMS int              `json:"execution_time_ms"`
+	MemoryUsageMB   int              `json:"memory_usage_mb"`
+	ErrorMessage    string           `json:"error_message,omitempty"`
+	FailedTest      int              `json:"failed_test,omitempty"` // 1-based, 0 if no test failed
+	// Tests are the results of the tests the submission was run on, in
+	// order; judging stops at the first test that does not pass.
+	Tests []TestResult `json:"tests,omitempty"`
+}
+
+// AggregateTests derives the verdict from its test results, if it has any:
+// the result is that of the first test that did not pass, or ok, and the time
+// and memory are the highest of any test. A verdict without test results,
+// such as a compile error, is left as it is.
+func (v *Verdict) AggregateTests() {
+	if len(v.Tests) == 0 {
+		return
+	}
+	v.Result, v.FailedTest = ResultOK, 0
+	v.ExecutionTimeMS, v.MemoryUsageMB = 0, 0
+	for _, test := range v.Tests {
+		v.ExecutionTimeMS = max(v.ExecutionTimeMS, test.ExecutionTimeMS)
+		v.MemoryUsageMB = max(v.MemoryUsageMB, test.MemoryUsageMB)
+		if test.Result != ResultOK && v.FailedTest == 0 {
+			v.Result, v.FailedTest = test.Result, test.TestNumber
+		}
+	}
+}
+
+// TestResult is the outcome of running a submission on one test case.
+type TestResult struct {
+	SubmissionID    int              `db:"submission_id" json:"-"`
+	TestNumber      int              `db:"test_number" json:"test_number"` // 1-based
+	IsSample        bool             `db:"is_sample" json:"is_sample"`
+	Result          SubmissionResult `db:"result" json:"result"`
+	ExecutionTimeMS int              `db:"execution_time_ms" json:"execution_time_ms"`
+	MemoryUsageMB   int              `db:"memory_usage_mb" json:"memory_usage_mb"`
+	ExitCode        int              `db:"exit_code" json:"exit_code"`
+	Signal          string           `db:"signal" json:"signal,omitempty"`
+	// Stdout and Stderr are the beginnings of the program's output.
+	Stdout string `db:"stdout" json:"stdout,omitempty"`
+	Stderr string `db:"stderr" json:"stderr,omitempty"`
+	// Message is what the checker, interactor or runner said about the test.
+	Message string `db:"message" json:"message,omitempty"`
+	// Redacted is set once Redact has removed the details.
+	Redacted bool `db:"-" json:"-"`
+}
+
+// Redact removes the program's output and the message from the result of a
+// hidden test, as they could reveal the test.
+func (t *TestResult) Redact() {
+	if t.IsSample {
+		return
+	}
+	t.Stdout, t.Stderr, t.Message = "", "", ""
+	t.Redacted = true
+}
+
+// UserStats summarizes a user's submissions for their profile.
+type UserStats struct {
+	Attempted   int `json:"attempted"` // distinct questions submitted to
+	Solved      int `json:"solved"`    // distinct questions with an accepted submission
+	Submissions int `json:"submissions"`
+	Accepted    int `json:"accepted"`
+	// AcceptanceRate is the share of judged submissions that were accepted,
+	// from 0 to 1. Submissions that failed with system_error do not count.
+	AcceptanceRate     float64                  `json:"acceptance_rate"`
+	Verdicts           map[SubmissionResult]int `json:"verdicts"`
+	SolvedByDifficulty map[string]int           `json:"solved_by_difficulty"`
+	// Activity counts submissions per UTC day, oldest first, leaving out
+	// days without any.
+	Activity []DayActivity `json:"activity"`
+}
+
+// DayActivity is the number of submissions a user made on one day.
+type DayActivity struct {
+	Date        string `json:"date" db:"day"` // YYYY-MM-DD
+	Submissions int    `json:"submissions" db:"submissions"`
+}
+
+// Session is a signed-in browser session of a user.
+type Session struct {
+	ID        string    `db:"id"` // SHA-256 of the session token, hex encoded
+	UserID    int       `db:"user_id"`
+	UserAgent string    `db:"user_agent"`
+	CreatedAt time.Time `db:"created_at"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
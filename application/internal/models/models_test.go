@@ -0,0 +1,139 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQuestionAccess(t *testing.T) {
+	owner := &User{ID: 1, Role: RoleRegular}
+	other := &User{ID: 2, Role: RoleRegular}
+	admin := &User{ID: 3, Role: RoleAdmin}
+
+	tests := []struct {
+		status      QuestionStatus
+		user        *User
+		wantVisible bool
+		wantEdit    bool
+	}{
+		{QuestionDraft, owner, true, true},
+		{QuestionDraft, other, false, false},
+		{QuestionDraft, admin, true, false},
+		{QuestionDraft, nil, false, false},
+		{QuestionReview, owner, true, false},
+		{QuestionReview, other, false, false},
+		{QuestionReview, admin, true, false},
+		{QuestionPublished, owner, true, false},
+		{QuestionPublished, other, true, false},
+		{QuestionPublished, nil, true, false},
+	}
+
+	for _, tt := range tests {
+		question := &Question{OwnerID: owner.ID, Status: tt.status}
+		if got := question.VisibleTo(tt.user); got != tt.wantVisible {
+			t.Errorf("%s question VisibleTo(%+v) = %v, want %v", tt.status, tt.user, got, tt.wantVisible)
+		}
+		if got := question.CanEdit(tt.user); got != tt.wantEdit {
+			t.Errorf("%s question CanEdit(%+v) = %v, want %v", tt.status, tt.user, got, tt.wantEdit)
+		}
+	}
+}
+
+func TestSubmissionAccess(t *testing.T) {
+	author := &User{ID: 1, Role: RoleRegular}
+	submission := &Submission{UserID: author.ID}
+
+	tests := []struct {
+		user *User
+		want bool
+	}{
+		{author, true},
+		{&User{ID: 2, Role: RoleRegular}, false},
+		{&User{ID: 3, Role: RoleAdmin}, true},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := submission.VisibleTo(tt.user); got != tt.want {
+			t.Errorf("VisibleTo(%+v) = %v, want %v", tt.user, got, tt.want)
+		}
+	}
+}
+
+func TestSubmissionOutcome(t *testing.T) {
+	wrong := ResultWrongAnswer
+
+	tests := []struct {
+		submission Submission
+		want       string
+	}{
+		{Submission{Status: SubmissionPending}, "pending"},
+		{Submission{Status: SubmissionProcessing}, "processing"},
+		{Submission{Status: SubmissionCompleted, Result: &wrong}, "wrong_answer"},
+		{Submission{Status: SubmissionCompleted}, "completed"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.submission.Outcome(); got != tt.want {
+			t.Errorf("Outcome of %+v = %q, want %q", tt.submission, got, tt.want)
+		}
+	}
+}
+
+func TestVerdictAggregateTests(t *testing.T) {
+	tests := []struct {
+		name    string
+		verdict Verdict
+		want    Verdict
+	}{
+		{
+			name:    "without tests",
+			verdict: Verdict{Result: ResultCompileError, ErrorMessage: "syntax error"},
+			want:    Verdict{Result: ResultCompileError, ErrorMessage: "syntax error"},
+		},
+		{
+			name: "all passed",
+			verdict: Verdict{Result: ResultWrongAnswer, FailedTest: 1, Tests: []TestResult{
+				{TestNumber: 1, Result: ResultOK, ExecutionTimeMS: 30, MemoryUsageMB: 5},
+				{TestNumber: 2, Result: ResultOK, ExecutionTimeMS: 10, MemoryUsageMB: 8},
+			}},
+			want: Verdict{Result: ResultOK, ExecutionTimeMS: 30, MemoryUsageMB: 8},
+		},
+		{
+			name: "first failure decides",
+			verdict: Verdict{Tests: []TestResult{
+				{TestNumber: 1, Result: ResultOK, ExecutionTimeMS: 10},
+				{TestNumber: 2, Result: ResultTimeLimitExceeded, ExecutionTimeMS: 1000},
+				{TestNumber: 3, Result: ResultWrongAnswer},
+			}},
+			want: Verdict{Result: ResultTimeLimitExceeded, FailedTest: 2, ExecutionTimeMS: 1000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.verdict.AggregateTests()
+			tt.verdict.Tests = nil
+			if !reflect.DeepEqual(tt.verdict, tt.want) {
+				t.Errorf("AggregateTests = %+v, want %+v", tt.verdict, tt.want)
+			}
+		})
+	}
+}
+
+func TestTestResultRedact(t *testing.T) {
+	hidden := TestResult{Result: ResultWrongAnswer, ExitCode: 1, Stdout: "42", Stderr: "debug", Message: "line 1 differs"}
+	hidden.Redact()
+	if hidden.Stdout != "" || hidden.Stderr != "" || hidden.Message != "" || !hidden.Redacted {
+		t.Errorf("hidden test after Redact = %+v", hidden)
+	}
+	if hidden.Result != ResultWrongAnswer || hidden.ExitCode != 1 {
+		t.Errorf("Redact removed the verdict: %+v", hidden)
+	}
+
+	sample := TestResult{IsSample: true, Stdout: "42"}
+	sample.Redact()
+	if sample.Stdout != "42" || sample.Redacted {
+		t.Errorf("sample test after Redact = %+v", sample)
+	}
+}
@@ -0,0 +1,35 @@
+package queue
+
+import "online-judge/internal/models"
+
+// Request bodies of the internal jobs API served by the web server under
+// /internal/jobs and used by Client.
+
+// ClaimRequest asks for the next pending submission.
+type ClaimRequest struct {
+	WorkerID string `json:"worker_id"`
+	// Languages are the languages the runner can judge; empty means any.
+	Languages []string `json:"languages,omitempty"`
+	// WaitMS is how long the server may hold the request open when no
+	// submission is pending, answering as soon as one is created.
+	WaitMS int `json:"wait_ms,omitempty"`
+}
+
+// HeartbeatRequest renews the lease of a claimed submission.
+type HeartbeatRequest struct {
+	WorkerID string `json:"worker_id"`
+}
+
+// ProgressRequest announces that a claimed submission passed its first
+// Passed tests out of Total.
+type ProgressRequest struct {
+	WorkerID string `json:"worker_id"`
+	Passed   int    `json:"passed"`
+	Total    int    `json:"total"`
+}
+
+// ResultRequest reports the verdict of a claimed submission.
+type ResultRequest struct {
+	WorkerID string         `json:"worker_id"`
+	Verdict  models.Verdict `json:"verdict"`
+}
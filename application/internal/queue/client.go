@@ -0,0 +1,124 @@
+package queue
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"online-judge/internal/models"
+)
+
+// requestTimeout bounds a single call to the internal jobs API.
+const requestTimeout = 30 * time.Second
+
+// claimWait is how long the server may hold a claim open waiting for a new
+// submission; it stays well below requestTimeout.
+const claimWait = 20 * time.Second
+
+// Client is a Source that talks to the web server's internal jobs API, so
+// runners need no database access.
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a Client for the server at baseURL authenticating with token.
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Claim implements Source.
+func (c *Client) Claim(ctx context.Context, workerID string, languages []string) (*Job, error) {
+	req := ClaimRequest{WorkerID: workerID, Languages: languages, WaitMS: int(claimWait.Milliseconds())}
+	resp, err := c.post(ctx, "/internal/jobs/claim", req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return nil, ErrNoJob
+	case http.StatusOK:
+		var job Job
+		if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+			return nil, fmt.Errorf("error decoding claimed job: %w", err)
+		}
+		return &job, nil
+	default:
+		return nil, statusError(resp)
+	}
+}
+
+// Heartbeat implements Source.
+func (c *Client) Heartbeat(ctx context.Context, submissionID int, workerID string) error {
+	path := fmt.Sprintf("/internal/jobs/%d/heartbeat", submissionID)
+	return c.postAcknowledged(ctx, path, HeartbeatRequest{WorkerID: workerID})
+}
+
+// Progress implements Source.
+func (c *Client) Progress(ctx context.Context, submissionID int, workerID string, passed, total int) error {
+	path := fmt.Sprintf("/internal/jobs/%d/progress", submissionID)
+	return c.postAcknowledged(ctx, path, ProgressRequest{WorkerID: workerID, Passed: passed, Total: total})
+}
+
+// Report implements Source.
+func (c *Client) Report(ctx context.Context, submissionID int, workerID string, verdict models.Verdict) error {
+	path := fmt.Sprintf("/internal/jobs/%d/result", submissionID)
+	return c.postAcknowledged(ctx, path, ResultRequest{WorkerID: workerID, Verdict: verdict})
+}
+
+// postAcknowledged posts body and expects 204, mapping 409 to ErrLeaseLost.
+func (c *Client) postAcknowledged(ctx context.Context, path string, body any) error {
+	resp, err := c.post(ctx, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return nil
+	case http.StatusConflict:
+		return ErrLeaseLost
+	default:
+		return statusError(resp)
+	}
+}
+
+func (c *Client) post(ctx context.Context, path string, body any) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling %s: %w", path, err)
+	}
+	return resp, nil
+}
+
+func statusError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("unexpected status %s from %s: %s",
+		resp.Status, resp.Request.URL.Path, strings.TrimSpace(string(body)))
+}
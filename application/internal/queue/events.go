@@ -0,0 +1,114 @@
+package queue
+
+import (
+	"sync"
+
+	"online-judge/internal/models"
+)
+
+// Kinds of Event, used as the event names of the submission event stream.
+const (
+	EventStatus   = "status"   // the submission became pending or processing
+	EventProgress = "progress" // the submission passed another test
+	EventVerdict  = "verdict"  // the submission was judged
+)
+
+// eventBuffer is how many undelivered events a subscriber may fall behind by
+// before its oldest ones are dropped.
+const eventBuffer = 16
+
+// Event is a change in the judging state of a submission. Each event carries
+// the whole state, so a subscriber that misses one loses nothing but a step.
+type Event struct {
+	Kind         string                  `json:"-"`
+	SubmissionID int                     `json:"submission_id"`
+	Status       models.SubmissionStatus `json:"status"`
+	// Passed and Total count the tests passed so far and the tests to run.
+	Passed int `json:"passed,omitempty"`
+	Total  int `json:"total,omitempty"`
+
+	Result          models.SubmissionResult `json:"result,omitempty"`
+	ExecutionTimeMS int                     `json:"execution_time_ms,omitempty"`
+	MemoryUsageMB   int                     `json:"memory_usage_mb,omitempty"`
+	FailedTest      int                     `json:"failed_test,omitempty"`
+}
+
+// Events fans out the events of the judging pipeline to the clients watching
+// each submission. The jobs API publishes to it as runners claim, progress
+// through and report submissions, and the Reaper as it releases them.
+type Events struct {
+	mu          sync.Mutex
+	subscribers map[int]map[chan Event]struct{}
+	closed      bool
+}
+
+// NewEvents creates an Events with no subscribers.
+func NewEvents() *Events {
+	return &Events{subscribers: make(map[int]map[chan Event]struct{})}
+}
+
+// Subscribe returns a channel receiving the events of a submission, and a
+// function to call once they are no longer wanted. The channel is closed
+// when the server shuts down.
+func (e *Events) Subscribe(submissionID int) (<-chan Event, func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ch := make(chan Event, eventBuffer)
+	if e.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	if e.subscribers[submissionID] == nil {
+		e.subscribers[submissionID] = make(map[chan Event]struct{})
+	}
+	e.subscribers[submissionID][ch] = struct{}{}
+
+	return ch, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		delete(e.subscribers[submissionID], ch)
+		if len(e.subscribers[submissionID]) == 0 {
+			delete(e.subscribers, submissionID)
+		}
+	}
+}
+
+// Publish delivers event to the subscribers of its submission without
+// blocking; a subscriber that has fallen behind loses its oldest event.
+func (e *Events) Publish(event Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for ch := range e.subscribers[event.SubmissionID] {
+		for sent := false; !sent; {
+			select {
+			case ch <- event:
+				sent = true
+			default:
+				select {
+				case <-ch:
+				default:
+				}
+			}
+		}
+	}
+}
+
+// Close ends every subscription, so that open streams finish when the
+// server shuts down.
+func (e *Events) Close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return
+	}
+	e.closed = true
+	for _, subscribers := range e.subscribers {
+		for ch := range subscribers {
+			close(ch)
+		}
+	}
+	e.subscribers = nil
+}
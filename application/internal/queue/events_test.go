@@ -0,0 +1,71 @@
+package queue
+
+import (
+	"testing"
+
+	"online-judge/internal/models"
+)
+
+func TestEventsDeliverToSubscribers(t *testing.T) {
+	events := NewEvents()
+	watched, unsubscribe := events.Subscribe(1)
+	other, _ := events.Subscribe(2)
+
+	events.Publish(Event{Kind: EventStatus, SubmissionID: 1, Status: models.SubmissionProcessing})
+	events.Publish(Event{Kind: EventProgress, SubmissionID: 1, Passed: 1, Total: 3})
+
+	if got := <-watched; got.Kind != EventStatus {
+		t.Errorf("first event = %+v, want the status", got)
+	}
+	if got := <-watched; got.Kind != EventProgress || got.Passed != 1 {
+		t.Errorf("second event = %+v, want progress on test 1", got)
+	}
+	select {
+	case got := <-other:
+		t.Errorf("subscriber of another submission got %+v", got)
+	default:
+	}
+
+	unsubscribe()
+	events.Publish(Event{Kind: EventVerdict, SubmissionID: 1})
+	select {
+	case got := <-watched:
+		t.Errorf("unsubscribed channel got %+v", got)
+	default:
+	}
+}
+
+func TestEventsDropOldestWhenBehind(t *testing.T) {
+	events := NewEvents()
+	ch, _ := events.Subscribe(1)
+
+	for passed := 1; passed <= eventBuffer+5; passed++ {
+		events.Publish(Event{Kind: EventProgress, SubmissionID: 1, Passed: passed})
+	}
+	events.Publish(Event{Kind: EventVerdict, SubmissionID: 1})
+
+	var last Event
+	for i := 0; i < eventBuffer; i++ {
+		last = <-ch
+	}
+	if last.Kind != EventVerdict {
+		t.Errorf("last buffered event = %+v, want the verdict", last)
+	}
+}
+
+func TestEventsClose(t *testing.T) {
+	events := NewEvents()
+	ch, unsubscribe := events.Subscribe(1)
+
+	events.Close()
+	if _, ok := <-ch; ok {
+		t.Error("subscription still open after Close")
+	}
+	unsubscribe()
+	events.Publish(Event{SubmissionID: 1})
+
+	late, _ := events.Subscribe(1)
+	if _, ok := <-late; ok {
+		t.Error("subscription after Close is open")
+	}
+}
@@ -0,0 +1,56 @@
+package queue
+
+import "sync"
+
+// Notifier wakes claimers waiting for a submission to become pending. The web
+// server notifies it whenever a submission is created, and the jobs API holds
+// empty claims open until then, so runners start judging at once instead of
+// on their next poll.
+type Notifier struct {
+	mu     sync.Mutex
+	ch     chan struct{}
+	closed bool
+}
+
+// NewNotifier creates a Notifier with nobody waiting.
+func NewNotifier() *Notifier {
+	return &Notifier{ch: make(chan struct{})}
+}
+
+// Notify wakes everyone waiting on a channel returned by Wait.
+func (n *Notifier) Notify() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		return
+	}
+	close(n.ch)
+	n.ch = make(chan struct{})
+}
+
+// Wait returns a channel that is closed by the next call to Notify or Close.
+// Callers should take the channel before checking for work, so that a
+// notification arriving in between is not missed.
+func (n *Notifier) Wait() <-chan struct{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.ch
+}
+
+// Close wakes all waiters for good, so that held claims return when the
+// server shuts down.
+func (n *Notifier) Close() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.closed {
+		n.closed = true
+		close(n.ch)
+	}
+}
+
+// Closed reports whether Close has been called.
+func (n *Notifier) Closed() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.closed
+}
@@ -0,0 +1,45 @@
+package queue
+
+import "testing"
+
+func TestNotifier(t *testing.T) {
+	n := NewNotifier()
+
+	first := n.Wait()
+	select {
+	case <-first:
+		t.Fatal("Wait returned a closed channel before Notify")
+	default:
+	}
+
+	n.Notify()
+	select {
+	case <-first:
+	default:
+		t.Fatal("Notify did not wake the waiter")
+	}
+
+	second := n.Wait()
+	select {
+	case <-second:
+		t.Fatal("Wait after Notify returned a closed channel")
+	default:
+	}
+
+	n.Close()
+	n.Close()
+	n.Notify()
+	select {
+	case <-second:
+	default:
+		t.Fatal("Close did not wake the waiter")
+	}
+	if !n.Closed() {
+		t.Error("Closed = false after Close")
+	}
+	select {
+	case <-n.Wait():
+	default:
+		t.Error("Wait after Close returned an open channel")
+	}
+}
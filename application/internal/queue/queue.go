@@ -0,0 +1,121 @@
+// Package queue hands pending submissions to runners and records their verdicts.
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"online-judge/internal/models"
+	"online-judge/internal/store"
+)
+
+var (
+	// ErrNoJob is returned by Claim when no submission is waiting to be judged.
+	ErrNoJob = errors.New("no pending submission")
+	// ErrLeaseLost is returned when a worker reports on a submission it no longer holds.
+	ErrLeaseLost = errors.New("submission lease lost")
+)
+
+// Job is a claimed submission with everything a runner needs to judge it.
+type Job struct {
+	SubmissionID  int               `json:"submission_id"`
+	Language      string            `json:"language"`
+	Code          string            `json:"code"`
+	TimeLimitMS   int               `json:"time_limit_ms"`
+	MemoryLimitMB int               `json:"memory_limit_mb"`
+	TestCases     []models.TestCase `json:"test_cases"`
+	Checker       models.Checker    `json:"checker"`
+	// Interactor is set for interactive questions, whose checker is unused.
+	Interactor *models.Interactor `json:"interactor,omitempty"`
+}
+
+// Source is what a worker needs from the judging queue.
+type Source interface {
+	// Claim leases the next pending submission written in one of languages,
+	// and judged by a checker or interactor in one of them, to workerID, or
+	// returns ErrNoJob. No languages means any language.
+	Claim(ctx context.Context, workerID string, languages []string) (*Job, error)
+	// Heartbeat extends the lease of a claimed submission.
+	Heartbeat(ctx context.Context, submissionID int, workerID string) error
+	// Progress announces that a claimed submission passed its first passed
+	// tests out of total.
+	Progress(ctx context.Context, submissionID int, workerID string, passed, total int) error
+	// Report records the verdict of a claimed submission.
+	Report(ctx context.Context, submissionID int, workerID string, verdict models.Verdict) error
+}
+
+// DBQueue is a Source backed directly by the database.
+type DBQueue struct {
+	submissions *store.SubmissionStore
+	questions   *store.QuestionStore
+	testCases   *store.TestCaseStore
+	lease       time.Duration
+}
+
+// NewDBQueue creates a DBQueue whose claims last for lease unless renewed.
+func NewDBQueue(db *sqlx.DB, lease time.Duration) *DBQueue {
+	return &DBQueue{
+		submissions: store.NewSubmissionStore(db),
+		questions:   store.NewQuestionStore(db),
+		testCases:   store.NewTestCaseStore(db),
+		lease:       lease,
+	}
+}
+
+// Claim implements Source.
+func (q *DBQueue) Claim(ctx context.Context, workerID string, languages []string) (*Job, error) {
+	submission, err := q.submissions.ClaimNext(ctx, workerID, languages, q.lease)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrNoJob
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	question, err := q.questions.GetByID(ctx, submission.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+	testCases, err := q.testCases.ListByQuestion(ctx, submission.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Job{
+		SubmissionID:  submission.ID,
+		Language:      submission.Language,
+		Code:          submission.Code,
+		TimeLimitMS:   question.TimeLimitMS,
+		MemoryLimitMB: question.MemoryLimitMB,
+		TestCases:     testCases,
+		Checker:       question.Checker(),
+		Interactor:    question.Interactor(),
+	}, nil
+}
+
+// Heartbeat implements Source.
+func (q *DBQueue) Heartbeat(ctx context.Context, submissionID int, workerID string) error {
+	return leaseError(q.submissions.ExtendLease(ctx, submissionID, workerID, q.lease))
+}
+
+// Progress implements Source. Progress is only streamed to clients by the
+// web server, so here it merely proves the worker alive and renews its lease.
+func (q *DBQueue) Progress(ctx context.Context, submissionID int, workerID string, _, _ int) error {
+	return q.Heartbeat(ctx, submissionID, workerID)
+}
+
+// Report implements Source.
+func (q *DBQueue) Report(ctx context.Context, submissionID int, workerID string, verdict models.Verdict) error {
+	return leaseError(q.submissions.Complete(ctx, submissionID, workerID, verdict))
+}
+
+func leaseError(err error) error {
+	if errors.Is(err, store.ErrNotFound) {
+		return fmt.Errorf("%w: %v", ErrLeaseLost, err)
+	}
+	return err
+}
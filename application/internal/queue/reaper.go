@@ -0,0 +1,90 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"online-judge/internal/models"
+	"online-judge/internal/store"
+)
+
+// Reaper returns submissions held by crashed or stalled runners to the queue,
+// and takes them out of it once they have failed too many times.
+type Reaper struct {
+	submissions *store.SubmissionStore
+	maxAttempts int
+	interval    time.Duration
+	// notifier, if set, wakes runners waiting for requeued submissions.
+	notifier *Notifier
+	// events, if set, tells the clients watching a released submission.
+	events *Events
+}
+
+// NewReaper creates a Reaper that checks for expired leases every interval
+// and gives up on a submission after maxAttempts expired leases. Either of
+// notifier and events may be nil.
+func NewReaper(db *sqlx.DB, maxAttempts int, interval time.Duration, notifier *Notifier, events *Events) *Reaper {
+	return &Reaper{
+		submissions: store.NewSubmissionStore(db),
+		maxAttempts: maxAttempts,
+		interval:    interval,
+		notifier:    notifier,
+		events:      events,
+	}
+}
+
+// Run reaps expired leases until ctx is cancelled.
+func (r *Reaper) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.reap(ctx)
+		}
+	}
+}
+
+func (r *Reaper) reap(ctx context.Context) {
+	released, err := r.submissions.ReleaseExpired(ctx, r.maxAttempts)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Error reaping expired submissions: %v", err)
+		}
+		return
+	}
+	if requeued := r.announce(released); len(released) > 0 {
+		log.Printf("Reaped expired submissions: %d requeued, %d failed with system_error", requeued, len(released)-requeued)
+	}
+}
+
+// announce publishes the new state of each released submission and wakes
+// the runners waiting for work if any went back to the queue. It returns how
+// many did.
+func (r *Reaper) announce(released []store.ReleasedSubmission) int {
+	requeued := 0
+	for _, submission := range released {
+		event := Event{Kind: EventStatus, SubmissionID: submission.ID, Status: submission.Status}
+		if submission.Status == models.SubmissionPending {
+			requeued++
+		} else {
+			event.Kind = EventVerdict
+			if submission.Result != nil {
+				event.Result = *submission.Result
+			}
+		}
+		if r.events != nil {
+			r.events.Publish(event)
+		}
+	}
+	if requeued > 0 && r.notifier != nil {
+		r.notifier.Notify()
+	}
+	return requeued
+}
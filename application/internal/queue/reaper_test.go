@@ -0,0 +1,53 @@
+package queue
+
+import (
+	"testing"
+
+	"online-judge/internal/models"
+	"online-judge/internal/store"
+)
+
+func TestReaperAnnounce(t *testing.T) {
+	events := NewEvents()
+	notifier := NewNotifier()
+	requeuedEvents, _ := events.Subscribe(1)
+	failedEvents, _ := events.Subscribe(2)
+	wake := notifier.Wait()
+
+	systemError := models.ResultSystemError
+	r := &Reaper{notifier: notifier, events: events}
+	requeued := r.announce([]store.ReleasedSubmission{
+		{ID: 1, Status: models.SubmissionPending},
+		{ID: 2, Status: models.SubmissionCompleted, Result: &systemError},
+	})
+
+	if requeued != 1 {
+		t.Errorf("announce() = %d, want 1 requeued", requeued)
+	}
+	if got := <-requeuedEvents; got.Kind != EventStatus || got.Status != models.SubmissionPending {
+		t.Errorf("requeued submission event = %+v, want a pending status", got)
+	}
+	if got := <-failedEvents; got.Kind != EventVerdict || got.Status != models.SubmissionCompleted || got.Result != systemError {
+		t.Errorf("failed submission event = %+v, want a system_error verdict", got)
+	}
+	select {
+	case <-wake:
+	default:
+		t.Error("runners were not woken for the requeued submission")
+	}
+}
+
+func TestReaperAnnounceOnlyFailures(t *testing.T) {
+	notifier := NewNotifier()
+	wake := notifier.Wait()
+
+	systemError := models.ResultSystemError
+	r := &Reaper{notifier: notifier}
+	r.announce([]store.ReleasedSubmission{{ID: 3, Status: models.SubmissionCompleted, Result: &systemError}})
+
+	select {
+	case <-wake:
+		t.Error("runners were woken although nothing was requeued")
+	default:
+	}
+}
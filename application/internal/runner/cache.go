@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"online-judge/internal/buildcache"
+	"online-judge/internal/languages"
+)
+
+// identifyToolchains runs the version command of each language that has one.
+// Languages whose version cannot be told are judged without the cache.
+func (r *Runner) identifyToolchains() map[string]string {
+	toolchains := make(map[string]string)
+	for _, id := range r.languageIDs {
+		language := r.languages[id]
+		if len(language.Compile) == 0 || len(language.Version) == 0 {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), r.compileTimeout)
+		cmd := exec.CommandContext(ctx, language.Version[0], language.Version[1:]...)
+		cmd.Env = append(os.Environ(), language.CompileEnv...)
+		version, err := cmd.CombinedOutput()
+		cancel()
+		if err != nil {
+			log.Printf("Warning: not caching %s builds, version unknown: %v", language.Name, err)
+			continue
+		}
+
+		// The command and environment are part of the toolchain, so that
+		// changing the compiler's options invalidates earlier builds.
+		parts := append([]string{string(version)}, language.Compile...)
+		toolchains[id] = strings.Join(append(parts, language.CompileEnv...), "\x00")
+	}
+	return toolchains
+}
+
+// buildKey returns the build cache key of code in language, or "" if its
+// builds are not cached.
+func (r *Runner) buildKey(language languages.Language, code string) string {
+	toolchain, ok := r.toolchains[language.ID]
+	if r.cache == nil || !ok {
+		return ""
+	}
+	return buildcache.Key(language.ID, toolchain, code)
+}
+
+// restoreBuild copies a cached build into workDir and reports whether there
+// was one.
+func (r *Runner) restoreBuild(key, workDir string) bool {
+	if key == "" {
+		return false
+	}
+	hit, err := r.cache.Get(key, workDir)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	return hit
+}
+
+// storeBuild caches the files a successful build left in workDir.
+func (r *Runner) storeBuild(key, workDir string, language languages.Language) {
+	if key == "" {
+		return
+	}
+	if err := r.cache.Put(key, workDir, language.SourceFile); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// BuildCacheStats returns the build cache's counters, or false when the cache
+// is disabled.
+func (r *Runner) BuildCacheStats() (buildcache.Stats, bool) {
+	if r.cache == nil {
+		return buildcache.Stats{}, false
+	}
+	return r.cache.Stats(), true
+}
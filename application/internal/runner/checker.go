@@ -0,0 +1,241 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"online-judge/internal/languages"
+	"online-judge/internal/models"
+)
+
+// Exit codes of custom checkers and interactors, following testlib.
+const (
+	checkerAccepted          = 0
+	checkerWrongAnswer       = 1
+	checkerPresentationError = 2
+)
+
+const (
+	// checkerTimeLimit and checkerMemoryMB bound a custom checker's run on
+	// one test, before its language's adjustments.
+	checkerTimeLimit = 10 * time.Second
+	checkerMemoryMB  = 256
+	// maxCheckerMessageBytes caps the checker or interactor message kept in
+	// a verdict.
+	maxCheckerMessageBytes = 1 << 10
+)
+
+// Files a custom checker is given as arguments, in this order.
+const (
+	checkerInputFile  = "input.txt"
+	checkerOutputFile = "output.txt"
+	checkerAnswerFile = "answer.txt"
+)
+
+// customChecker is a compiled checker program of a question.
+type customChecker struct {
+	dir      string
+	language languages.Language
+	limits   limits
+}
+
+// validateChecker returns the language of a custom checker, or an error if
+// this runner cannot apply the checker.
+func (r *Runner) validateChecker(checker models.Checker) (languages.Language, error) {
+	switch checker.Type {
+	case "", models.CheckerExact, models.CheckerWhitespace, models.CheckerTokens, models.CheckerFloat,
+		models.CheckerCaseInsensitive, models.CheckerUnorderedLines:
+		return languages.Language{}, nil
+	case models.CheckerCustom:
+		language, ok := r.languages[checker.Language]
+		if !ok {
+			return language, fmt.Errorf("checker language %q is not available on this runner", checker.Language)
+		}
+		return language, nil
+	}
+	return languages.Language{}, fmt.Errorf("unknown checker %q", checker.Type)
+}
+
+// buildChecker compiles the source of a custom checker in dir. When the
+// checker does not compile, errCompile is returned with the compiler output.
+func (r *Runner) buildChecker(ctx context.Context, dir string, language languages.Language, source string) (*customChecker, string, error) {
+	message, err := r.compile(ctx, dir, language, source)
+	if err != nil {
+		return nil, message, err
+	}
+	limits := r.limitsFor(Job{TimeLimit: checkerTimeLimit, MemoryLimitMB: checkerMemoryMB}, language)
+	return &customChecker{dir: dir, language: language, limits: limits}, "", nil
+}
+
+// check judges the output of a program that ran successfully on a test,
+// with the custom checker if there is one and the job's built-in checker
+// otherwise.
+func (r *Runner) check(ctx context.Context, job Job, custom *customChecker, testCase models.TestCase, output string) (models.SubmissionResult, string, error) {
+	if custom != nil {
+		return custom.run(ctx, testCase, output)
+	}
+	if ok, message := compareOutput(job.Checker, testCase.ExpectedOutput, output); !ok {
+		return models.ResultWrongAnswer, message, nil
+	}
+	return models.ResultOK, "", nil
+}
+
+// run gives the checker the test's input, the program's output and the
+// expected output, and turns its exit code into a verdict. A checker that
+// crashes or runs out of time fails the submission with system_error, since
+// the fault is not the contestant's.
+func (c *customChecker) run(ctx context.Context, testCase models.TestCase, output string) (models.SubmissionResult, string, error) {
+	files := map[string]string{
+		checkerInputFile:  testCase.Input,
+		checkerOutputFile: output,
+		checkerAnswerFile: testCase.ExpectedOutput,
+	}
+	for name, content := range files {
+		if err := writeSandboxFile(filepath.Join(c.dir, name), content); err != nil {
+			return "", "", fmt.Errorf("error writing checker file: %w", err)
+		}
+	}
+
+	command := append(append([]string(nil), c.language.Run...), checkerInputFile, checkerOutputFile, checkerAnswerFile)
+	execution, err := execute(ctx, invocation{dir: c.dir, command: command, limits: c.limits}, "")
+	if err != nil {
+		return "", "", fmt.Errorf("error running checker: %w", err)
+	}
+
+	message := programMessage(execution.Stderr)
+	if message == "" {
+		message = programMessage(execution.Stdout)
+	}
+
+	switch {
+	case execution.TimedOut || execution.Signal != "":
+		return models.ResultSystemError, "checker failed: " + execution.Failure(), nil
+	case execution.ExitCode == checkerAccepted:
+		return models.ResultOK, message, nil
+	case execution.ExitCode == checkerWrongAnswer || execution.ExitCode == checkerPresentationError:
+		if message == "" {
+			message = "output rejected by the checker"
+		}
+		return models.ResultWrongAnswer, message, nil
+	}
+	return models.ResultSystemError, "checker failed: " + execution.Failure(), nil
+}
+
+// programMessage turns what a checker or interactor wrote into the message
+// of a verdict.
+func programMessage(output string) string {
+	message := strings.TrimSpace(output)
+	if len(message) > maxCheckerMessageBytes {
+		message = message[:maxCheckerMessageBytes] + "…"
+	}
+	return message
+}
+
+// compareOutput compares a program's output with the expected output using
+// one of the built-in checkers. The message locates the first difference
+// without revealing the expected output, which may belong to a hidden test.
+func compareOutput(checker models.Checker, expected, actual string) (bool, string) {
+	switch checker.Type {
+	case models.CheckerExact:
+		if expected == actual {
+			return true, ""
+		}
+		return compareLines(strings.Split(expected, "\n"), strings.Split(actual, "\n"), stringsEqual)
+	case models.CheckerTokens:
+		return compareTokens(strings.Fields(expected), strings.Fields(actual), stringsEqual)
+	case models.CheckerFloat:
+		return compareTokens(strings.Fields(expected), strings.Fields(actual), func(e, a string) bool {
+			return floatsMatch(e, a, checker.Epsilon)
+		})
+	case models.CheckerCaseInsensitive:
+		return compareLines(outputLines(expected), outputLines(actual), strings.EqualFold)
+	case models.CheckerUnorderedLines:
+		expectedLines, actualLines := outputLines(expected), outputLines(actual)
+		sort.Strings(expectedLines)
+		sort.Strings(actualLines)
+		if ok, _ := compareLines(expectedLines, actualLines, stringsEqual); !ok {
+			if len(expectedLines) != len(actualLines) {
+				return false, fmt.Sprintf("expected %d lines, got %d", len(expectedLines), len(actualLines))
+			}
+			return false, "lines do not match the expected lines in any order"
+		}
+		return true, ""
+	}
+	return compareLines(outputLines(expected), outputLines(actual), stringsEqual)
+}
+
+func stringsEqual(a, b string) bool {
+	return a == b
+}
+
+// outputLines splits output into lines without trailing whitespace, leaving
+// out trailing blank lines.
+func outputLines(output string) []string {
+	normalized := normalizeOutput(output)
+	if normalized == "" {
+		return nil
+	}
+	return strings.Split(normalized, "\n")
+}
+
+// compareLines reports the first of the lines that differ.
+func compareLines(expected, actual []string, equal func(expected, actual string) bool) (bool, string) {
+	for i := 0; i < len(expected) && i < len(actual); i++ {
+		if !equal(expected[i], actual[i]) {
+			return false, fmt.Sprintf("line %d differs from the expected output", i+1)
+		}
+	}
+	if len(expected) != len(actual) {
+		return false, fmt.Sprintf("expected %d lines, got %d", len(expected), len(actual))
+	}
+	return true, ""
+}
+
+// compareTokens reports the first of the tokens that differ.
+func compareTokens(expected, actual []string, equal func(expected, actual string) bool) (bool, string) {
+	for i := 0; i < len(expected) && i < len(actual); i++ {
+		if !equal(expected[i], actual[i]) {
+			return false, fmt.Sprintf("token %d differs from the expected output", i+1)
+		}
+	}
+	if len(expected) != len(actual) {
+		return false, fmt.Sprintf("expected %d tokens, got %d", len(expected), len(actual))
+	}
+	return true, ""
+}
+
+// floatsMatch reports whether actual is within an absolute or relative error
+// of epsilon from expected. Tokens of the expected output that are not
+// numbers must match exactly.
+func floatsMatch(expected, actual string, epsilon float64) bool {
+	want, err := strconv.ParseFloat(expected, 64)
+	if err != nil {
+		return expected == actual
+	}
+	got, err := strconv.ParseFloat(actual, 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return false
+	}
+	if math.IsNaN(got) || math.IsInf(got, 0) || math.IsInf(want, 0) {
+		return got == want
+	}
+	diff := math.Abs(got - want)
+	return diff <= epsilon || diff <= epsilon*math.Abs(want)
+}
+
+// writeSandboxFile writes a file that, besides the runner, only the user of
+// sandboxed programs can read.
+func writeSandboxFile(path, content string) error {
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		return err
+	}
+	return ownBySandbox(path)
+}
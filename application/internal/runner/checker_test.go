@@ -0,0 +1,146 @@
+package runner
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"online-judge/internal/models"
+)
+
+func TestCompareOutput(t *testing.T) {
+	tests := []struct {
+		name        string
+		checker     models.Checker
+		expected    string
+		actual      string
+		wantOK      bool
+		wantMessage string
+	}{
+		{"default ignores trailing whitespace", models.Checker{}, "1 2\n3", "1 2  \n3\n\n", true, ""},
+		{"whitespace reports the line", models.Checker{Type: models.CheckerWhitespace}, "1\n2\n3", "1\n5\n3", false, "line 2"},
+		{"whitespace counts lines", models.Checker{Type: models.CheckerWhitespace}, "1\n2", "1", false, "expected 2 lines, got 1"},
+		{"exact accepts identical output", models.Checker{Type: models.CheckerExact}, "1 2\n", "1 2\n", true, ""},
+		{"exact rejects trailing space", models.Checker{Type: models.CheckerExact}, "1 2\n", "1 2 \n", false, "line 1"},
+		{"exact rejects a missing newline", models.Checker{Type: models.CheckerExact}, "1 2\n", "1 2", false, "expected 2 lines, got 1"},
+		{"tokens ignore layout", models.Checker{Type: models.CheckerTokens}, "1 2 3", "1\n2   3\n", true, ""},
+		{"tokens report the token", models.Checker{Type: models.CheckerTokens}, "1 2 3", "1 2 4", false, "token 3"},
+		{"tokens count tokens", models.Checker{Type: models.CheckerTokens}, "1 2 3", "1 2", false, "expected 3 tokens, got 2"},
+		{"float within absolute error", models.Checker{Type: models.CheckerFloat, Epsilon: 1e-6}, "0.333333", "0.3333334", true, ""},
+		{"float within relative error", models.Checker{Type: models.CheckerFloat, Epsilon: 1e-6}, "1000000000", "1000000100", true, ""},
+		{"float outside both errors", models.Checker{Type: models.CheckerFloat, Epsilon: 1e-6}, "1.5", "1.5001", false, "token 1"},
+		{"float compares words exactly", models.Checker{Type: models.CheckerFloat, Epsilon: 1e-6}, "YES 2.0", "YES 2", true, ""},
+		{"float rejects other words", models.Checker{Type: models.CheckerFloat, Epsilon: 1e-6}, "YES 2.0", "yes 2", false, "token 1"},
+		{"float rejects NaN", models.Checker{Type: models.CheckerFloat, Epsilon: 1e-6}, "1", "NaN", false, "token 1"},
+		{"case-insensitive", models.Checker{Type: models.CheckerCaseInsensitive}, "Yes\nNO", "yes\nno \n", true, ""},
+		{"case-insensitive still compares letters", models.Checker{Type: models.CheckerCaseInsensitive}, "Yes", "Yep", false, "line 1"},
+		{"unordered lines", models.Checker{Type: models.CheckerUnorderedLines}, "a\nb\nc", "c\na\nb\n", true, ""},
+		{"unordered lines keep duplicates", models.Checker{Type: models.CheckerUnorderedLines}, "a\na\nb", "a\nb\nb", false, "any order"},
+		{"unordered lines count lines", models.Checker{Type: models.CheckerUnorderedLines}, "a\nb", "b", false, "expected 2 lines, got 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, message := compareOutput(tt.checker, tt.expected, tt.actual)
+			if ok != tt.wantOK {
+				t.Fatalf("compareOutput = %v (%q), want %v", ok, message, tt.wantOK)
+			}
+			if !strings.Contains(message, tt.wantMessage) {
+				t.Errorf("message = %q, want it to mention %q", message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+// sumChecker accepts any two numbers that add up to the number in the
+// expected output, which keeps it from being a plain comparison.
+const sumChecker = `#include <stdio.h>
+int main(int argc, char **argv) {
+	FILE *output = fopen(argv[2], "r"), *answer = fopen(argv[3], "r");
+	long a, b, sum;
+	if (fscanf(answer, "%ld", &sum) != 1) return 3;
+	if (fscanf(output, "%ld %ld", &a, &b) != 2) { fprintf(stderr, "expected two numbers"); return 2; }
+	if (a + b != sum) { fprintf(stderr, "%ld + %ld is not %ld", a, b, sum); return 1; }
+	return 0;
+}`
+
+func TestJudgeWithCustomChecker(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles and runs programs")
+	}
+	r := newTestRunner(t)
+	if _, ok := r.languages["c"]; !ok {
+		t.Skip("c is not installed on this host")
+	}
+
+	tests := []struct {
+		name        string
+		checker     string
+		code        string
+		wantVerdict models.SubmissionResult
+		wantMessage string
+	}{
+		{
+			name:        "accepted",
+			checker:     sumChecker,
+			code:        `#include <stdio.h>` + "\nint main(void) { long n; scanf(\"%ld\", &n); printf(\"%ld 1\\n\", n - 1); return 0; }",
+			wantVerdict: models.ResultOK,
+		},
+		{
+			name:        "rejected with the checker's message",
+			checker:     sumChecker,
+			code:        `#include <stdio.h>` + "\nint main(void) { puts(\"2 2\"); return 0; }",
+			wantVerdict: models.ResultWrongAnswer,
+			wantMessage: "2 + 2 is not 10",
+		},
+		{
+			name:        "checker that does not compile",
+			checker:     "int main(void) { return undefined(); }",
+			code:        `#include <stdio.h>` + "\nint main(void) { puts(\"5 5\"); return 0; }",
+			wantVerdict: models.ResultSystemError,
+			wantMessage: "checker does not compile",
+		},
+		{
+			name:        "checker that fails",
+			checker:     "int main(void) { return 3; }",
+			code:        `#include <stdio.h>` + "\nint main(void) { puts(\"5 5\"); return 0; }",
+			wantVerdict: models.ResultSystemError,
+			wantMessage: "checker failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := r.Judge(context.Background(), Job{
+				Language:      "c",
+				Code:          tt.code,
+				TestCases:     []models.TestCase{{Input: "10\n", ExpectedOutput: "10\n"}},
+				TimeLimit:     time.Second,
+				MemoryLimitMB: 64,
+				Checker:       models.Checker{Type: models.CheckerCustom, Language: "c", Source: tt.checker},
+			})
+			if err != nil {
+				t.Fatalf("Judge: %v", err)
+			}
+			if result.Verdict != tt.wantVerdict {
+				t.Errorf("Verdict = %s, want %s (message: %s)", result.Verdict, tt.wantVerdict, result.Message)
+			}
+			if !strings.Contains(result.Message, tt.wantMessage) {
+				t.Errorf("Message = %q, want it to mention %q", result.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestJudgeRejectsUnavailableChecker(t *testing.T) {
+	r := newTestRunner(t)
+	for _, checker := range []models.Checker{
+		{Type: "regex"},
+		{Type: models.CheckerCustom, Language: "cobol", Source: "x"},
+	} {
+		if _, err := r.Judge(context.Background(), Job{Language: "go", Code: "x", Checker: checker}); err == nil {
+			t.Errorf("Judge accepted checker %+v", checker)
+		}
+	}
+}
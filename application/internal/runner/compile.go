@@ -0,0 +1,143 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"online-judge/internal/languages"
+)
+
+// errCompile marks a build that failed because of the submitted code.
+var errCompile = errors.New("compilation failed")
+
+const (
+	// compileMemoryMB, compileProcesses and compileFileSizeMB bound a
+	// compiler's run in the sandbox, on top of runner.timeout.
+	compileMemoryMB   = 1024
+	compileProcesses  = 256
+	compileFileSizeMB = 256
+)
+
+// compile writes code to workDir and builds it with the language's compile
+// command, if it has one, unless the build cache holds the result of an
+// identical build. When the code does not compile, errCompile is returned
+// with the compiler output.
+func (r *Runner) compile(ctx context.Context, workDir string, language languages.Language, code string) (string, error) {
+	source := filepath.Join(workDir, language.SourceFile)
+	if err := os.WriteFile(source, []byte(code), 0o644); err != nil {
+		return "", fmt.Errorf("error writing source: %w", err)
+	}
+	// The sandboxed compiler writes its output here as nobody.
+	if err := ownBySandbox(workDir); err != nil {
+		return "", fmt.Errorf("error preparing work directory: %w", err)
+	}
+
+	if len(language.Compile) > 0 {
+		key := r.buildKey(language, code)
+		if !r.restoreBuild(key, workDir) {
+			if message, err := r.build(ctx, workDir, language); err != nil {
+				return message, err
+			}
+			r.storeBuild(key, workDir, language)
+		}
+	}
+	return "", nil
+}
+
+// build runs the compile command of language in workDir inside the sandbox,
+// with the language's home directory and none of the runner's environment.
+// A compiler that runs out of time is killed and reported like a failed
+// build, as the code is to blame.
+func (r *Runner) build(ctx context.Context, workDir string, language languages.Language) (string, error) {
+	compileCtx, cancel := context.WithTimeout(ctx, r.compileTimeout)
+	defer cancel()
+
+	env := append([]string{"PATH=" + r.compilePath, "HOME=" + sandboxHome, "TMPDIR=/tmp"}, language.CompileEnv...)
+	execution, err := execute(compileCtx, invocation{
+		dir:     workDir,
+		command: language.Compile,
+		limits: limits{
+			Time:       r.compileTimeout,
+			MemoryMB:   compileMemoryMB,
+			CPUs:       runtime.NumCPU(),
+			Processes:  compileProcesses,
+			FileSizeMB: compileFileSizeMB,
+		},
+		writable: true,
+		home:     r.homes[language.ID],
+		env:      env,
+	}, "")
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("error compiling: %w", ctx.Err())
+	}
+	if compileCtx.Err() != nil || err == nil && execution.TimedOut {
+		return "compilation timed out", errCompile
+	}
+	if err != nil {
+		return "", fmt.Errorf("error compiling: %w", err)
+	}
+	if execution.ExitCode == 0 && execution.Signal == "" {
+		return "", nil
+	}
+
+	output := newLimitedBuffer(maxMessageBytes)
+	output.Write([]byte(execution.Stdout + execution.Stderr))
+	if execution.Signal != "" {
+		fmt.Fprintf(output, "compiler killed by signal %s\n", execution.Signal)
+	}
+	return strings.ReplaceAll(output.String(), sandboxDir+"/", ""), errCompile
+}
+
+// prepareHomes creates a private home directory under dir for the compiler
+// of each compiled language.
+func (r *Runner) prepareHomes(dir string) error {
+	if err := privateDir(dir); err != nil {
+		return err
+	}
+	r.homes = make(map[string]string)
+	for _, id := range r.languageIDs {
+		if len(r.languages[id].Compile) == 0 {
+			continue
+		}
+		home := filepath.Join(dir, id)
+		if err := os.Mkdir(home, 0o700); err != nil && !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("error creating home for %s compiler: %w", id, err)
+		}
+		if err := ownBySandbox(home); err != nil {
+			return fmt.Errorf("error preparing home for %s compiler: %w", id, err)
+		}
+		r.homes[id] = home
+	}
+	return nil
+}
+
+// privateDir creates dir if needed and checks that only the runner's user
+// can use it.
+func privateDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("error creating %s: %w", dir, err)
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() || info.Mode().Perm() != 0o700 || !ownedByRunner(info) {
+		return fmt.Errorf("%s must be a directory owned by the runner's user with mode 0700", dir)
+	}
+	return nil
+}
+
+// ownBySandbox hands path over to nobody when the runner is root, so that
+// sandboxed programs may write to it. Otherwise they already run as the
+// runner's user on the host.
+func ownBySandbox(path string) error {
+	if os.Geteuid() != 0 {
+		return nil
+	}
+	return os.Chown(path, nobodyID, nobodyID)
+}
@@ -0,0 +1,99 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// sandboxPath is the PATH of sandboxed programs. Interpreters named by a
+// language's run command are looked up there, and must be usable by the
+// sandbox's unprivileged user.
+const sandboxPath = "/usr/bin:/bin"
+
+// nobodyID is the uid/gid sandboxed programs run as, inside their user
+// namespace and, when the runner is root, on the host.
+const nobodyID = 65534
+
+const (
+	// sandboxDir is where the program's directory appears in the sandbox.
+	sandboxDir = "/box"
+	// sandboxHome is where the home directory of a compiler, if any,
+	// appears in the sandbox.
+	sandboxHome = "/home"
+)
+
+// limits are the resources a single test execution may use.
+type limits struct {
+	Time     time.Duration
+	MemoryMB int
+	CPUs     int
+	// Processes caps the processes and threads the program may have at once.
+	Processes int
+	// FileSizeMB caps the size of any file the program writes; 0 forbids
+	// writing files at all.
+	FileSizeMB int
+}
+
+// invocation is a program to run in the sandbox: command, run in dir within
+// limits.
+type invocation struct {
+	dir     string
+	command []string
+	limits  limits
+	// writable lets the program write to dir, as compilers must.
+	writable bool
+	// home, if set, is a host directory the program may write to as its
+	// home, sandboxHome.
+	home string
+	// env is added to the program's environment, overriding its PATH and
+	// GOMAXPROCS if it sets them.
+	env []string
+}
+
+// execution is the observed outcome of running a program on one input.
+type execution struct {
+	Stdout          string
+	Stderr          string
+	OutputTruncated bool
+	ExitCode        int
+	Signal          string
+	TimedOut        bool
+	CPUTime         time.Duration
+	WallTime        time.Duration
+	MemoryKB        int
+}
+
+// OutOfMemory reports whether the program's runtime aborted because an
+// allocation was refused by the memory rlimit.
+func (e *execution) OutOfMemory() bool {
+	for _, message := range outOfMemoryMessages {
+		if strings.Contains(e.Stderr, message) {
+			return true
+		}
+	}
+	return false
+}
+
+// outOfMemoryMessages are printed by the runtimes of the built-in languages
+// when an allocation fails.
+var outOfMemoryMessages = []string{
+	"out of memory",              // Go
+	"cannot allocate memory",     // Go, C
+	"std::bad_alloc",             // C++
+	"MemoryError",                // Python
+	"java.lang.OutOfMemoryError", // Java
+	"memory allocation of",       // Rust
+}
+
+// Failure describes a non-zero exit for the submission's error message.
+func (e *execution) Failure() string {
+	summary := fmt.Sprintf("exit code %d", e.ExitCode)
+	if e.Signal != "" {
+		summary = "killed by signal " + e.Signal
+	}
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		return summary + "\n" + stderr
+	}
+	return summary
+}
@@ -0,0 +1,94 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"online-judge/internal/languages"
+	"online-judge/internal/models"
+)
+
+// Files an interactor is given as arguments, in this order.
+const (
+	interactorInputFile  = "input.txt"
+	interactorAnswerFile = "answer.txt"
+)
+
+// interactor is the compiled interactor program of an interactive question.
+// It uses the exit codes of custom checkers.
+type interactor struct {
+	dir      string
+	language languages.Language
+	limits   limits
+}
+
+// buildInteractor compiles the source of an interactor in dir. When the
+// interactor does not compile, errCompile is returned with the compiler
+// output. The interactor gets the time of the program it talks with on top
+// of the checker limits, since it mostly waits for the program.
+func (r *Runner) buildInteractor(ctx context.Context, dir string, language languages.Language, source string, programLimits limits) (*interactor, string, error) {
+	message, err := r.compile(ctx, dir, language, source)
+	if err != nil {
+		return nil, message, err
+	}
+	limits := r.limitsFor(Job{TimeLimit: checkerTimeLimit + programLimits.Time, MemoryLimitMB: checkerMemoryMB}, language)
+	return &interactor{dir: dir, language: language, limits: limits}, "", nil
+}
+
+// run runs command, the submitted program, in workDir within limits while
+// the interactor talks with it on the test.
+func (it *interactor) run(ctx context.Context, workDir string, command []string, testCase models.TestCase, limits limits) (*execution, models.SubmissionResult, string, error) {
+	files := map[string]string{
+		interactorInputFile:  testCase.Input,
+		interactorAnswerFile: testCase.ExpectedOutput,
+	}
+	for name, content := range files {
+		if err := writeSandboxFile(filepath.Join(it.dir, name), content); err != nil {
+			return nil, "", "", fmt.Errorf("error writing interactor file: %w", err)
+		}
+	}
+
+	program, interaction, err := interact(ctx,
+		invocation{dir: workDir, command: command, limits: limits},
+		invocation{
+			dir:     it.dir,
+			command: append(append([]string(nil), it.language.Run...), interactorInputFile, interactorAnswerFile),
+			limits:  it.limits,
+		})
+	if err != nil {
+		return nil, "", "", err
+	}
+	verdict, message := judgeInteraction(program, interaction, limits)
+	return program, verdict, message, nil
+}
+
+// judgeInteraction turns the executions of a program and its interactor on
+// one test into a verdict. The program's own limits come first, since a
+// program killed for exceeding them leaves the interactor without an
+// answer. A rejection by the interactor comes before the program's runtime
+// errors, which are often only the program writing to an interactor that
+// has already given up on it.
+func judgeInteraction(program, interaction *execution, limits limits) (models.SubmissionResult, string) {
+	if verdict, message := judgeExecution(program, limits); verdict == models.ResultTimeLimitExceeded || verdict == models.ResultMemoryLimitExceeded {
+		return verdict, message
+	}
+
+	message := programMessage(interaction.Stderr)
+	switch {
+	case interaction.TimedOut || interaction.Signal != "":
+		return models.ResultSystemError, "interactor failed: " + interaction.Failure()
+	case interaction.ExitCode == checkerWrongAnswer || interaction.ExitCode == checkerPresentationError:
+		if message == "" {
+			message = "rejected by the interactor"
+		}
+		return models.ResultWrongAnswer, message
+	case interaction.ExitCode != checkerAccepted:
+		return models.ResultSystemError, "interactor failed: " + interaction.Failure()
+	}
+
+	if verdict, message := judgeExecution(program, limits); verdict != models.ResultOK {
+		return verdict, message
+	}
+	return models.ResultOK, message
+}
@@ -0,0 +1,171 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"online-judge/internal/models"
+)
+
+func TestJudgeInteraction(t *testing.T) {
+	limits := limits{Time: time.Second, MemoryMB: 64}
+	tests := []struct {
+		name        string
+		program     execution
+		interaction execution
+		wantVerdict models.SubmissionResult
+		wantMessage string
+	}{
+		{"accepted", execution{}, execution{Stderr: "3 queries"}, models.ResultOK, "3 queries"},
+		{"rejected", execution{}, execution{ExitCode: 1, Stderr: "wrong guess\n"}, models.ResultWrongAnswer, "wrong guess"},
+		{"rejected without a message", execution{}, execution{ExitCode: 2}, models.ResultWrongAnswer, "rejected by the interactor"},
+		{"time limit comes first", execution{TimedOut: true, Signal: "killed"}, execution{ExitCode: 1}, models.ResultTimeLimitExceeded, "time limit"},
+		{"memory limit comes first", execution{MemoryKB: 100 << 10}, execution{ExitCode: 1}, models.ResultMemoryLimitExceeded, "memory limit"},
+		{"rejection before broken pipe", execution{Signal: "broken pipe"}, execution{ExitCode: 1}, models.ResultWrongAnswer, ""},
+		{"runtime error after acceptance", execution{ExitCode: 3}, execution{}, models.ResultRuntimeError, "exit code 3"},
+		{"interactor failed", execution{}, execution{ExitCode: 3, Stderr: "bad test"}, models.ResultSystemError, "interactor failed: exit code 3"},
+		{"interactor crashed", execution{}, execution{Signal: "segmentation fault"}, models.ResultSystemError, "interactor failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict, message := judgeInteraction(&tt.program, &tt.interaction, limits)
+			if verdict != tt.wantVerdict {
+				t.Errorf("verdict = %s (%q), want %s", verdict, message, tt.wantVerdict)
+			}
+			if !strings.Contains(message, tt.wantMessage) {
+				t.Errorf("message = %q, want it to mention %q", message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+// guessInteractor hides the number in the test input and answers up to 20
+// guesses with <, > or =.
+const guessInteractor = `#include <stdio.h>
+int main(int argc, char **argv) {
+	FILE *input = fopen(argv[1], "r");
+	long secret, guess;
+	if (fscanf(input, "%ld", &secret) != 1) return 3;
+	for (int i = 1; i <= 20; i++) {
+		if (scanf("%ld", &guess) != 1) { fprintf(stderr, "expected a guess"); return 2; }
+		if (guess == secret) { puts("="); fprintf(stderr, "found in %d guesses", i); return 0; }
+		puts(guess < secret ? ">" : "<");
+		fflush(stdout);
+	}
+	fprintf(stderr, "too many guesses");
+	return 1;
+}`
+
+// guessProgram returns a C program that guesses numbers from 1 to 1000000
+// one by one, or by binary search if linear is false, and exits with
+// exitCode once it finds the number.
+func guessProgram(linear bool, exitCode int) string {
+	step := 0
+	if linear {
+		step = 1
+	}
+	return fmt.Sprintf(`#include <stdio.h>
+int main(void) {
+	long low = 1, high = 1000000, step = %d;
+	char reply[2];
+	for (;;) {
+		long guess = step ? low : (low + high) / 2;
+		printf("%%ld\n", guess);
+		fflush(stdout);
+		if (scanf("%%1s", reply) != 1) return 0;
+		if (reply[0] == '=') return %d;
+		if (reply[0] == '>') low = step ? low + step : guess + 1; else high = guess - 1;
+	}
+}`, step, exitCode)
+}
+
+func TestJudgeInteractive(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles and runs programs")
+	}
+	r := newTestRunner(t)
+	if _, ok := r.languages["c"]; !ok {
+		t.Skip("c is not installed on this host")
+	}
+
+	tests := []struct {
+		name           string
+		interactor     string
+		code           string
+		wantVerdict    models.SubmissionResult
+		wantMessage    string
+		wantFailedTest int
+	}{
+		{
+			name:        "accepted",
+			interactor:  guessInteractor,
+			code:        guessProgram(false, 0),
+			wantVerdict: models.ResultOK,
+		},
+		{
+			name:           "rejected by the interactor",
+			interactor:     guessInteractor,
+			code:           guessProgram(true, 0),
+			wantVerdict:    models.ResultWrongAnswer,
+			wantMessage:    "too many guesses",
+			wantFailedTest: 2,
+		},
+		{
+			name:           "waiting for an answer that never comes",
+			interactor:     guessInteractor,
+			code:           "#include <stdio.h>\nint main(void) { char reply[2]; scanf(\"%1s\", reply); return 0; }",
+			wantVerdict:    models.ResultTimeLimitExceeded,
+			wantFailedTest: 1,
+		},
+		{
+			name:           "runtime error after the interaction",
+			interactor:     guessInteractor,
+			code:           guessProgram(false, 3),
+			wantVerdict:    models.ResultRuntimeError,
+			wantMessage:    "exit code 3",
+			wantFailedTest: 1,
+		},
+		{
+			name:        "interactor that does not compile",
+			interactor:  "int main(void) { return undefined(); }",
+			code:        guessProgram(false, 0),
+			wantVerdict: models.ResultSystemError,
+			wantMessage: "interactor does not compile",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := r.Judge(context.Background(), Job{
+				Language:      "c",
+				Code:          tt.code,
+				TestCases:     []models.TestCase{{Input: "15\n"}, {Input: "765432\n"}},
+				TimeLimit:     time.Second,
+				MemoryLimitMB: 64,
+				Interactor:    &models.Interactor{Language: "c", Source: tt.interactor},
+			})
+			if err != nil {
+				t.Fatalf("Judge: %v", err)
+			}
+			if result.Verdict != tt.wantVerdict || result.FailedTest != tt.wantFailedTest {
+				t.Errorf("Verdict = %s on test %d, want %s on test %d (message: %s)",
+					result.Verdict, result.FailedTest, tt.wantVerdict, tt.wantFailedTest, result.Message)
+			}
+			if !strings.Contains(result.Message, tt.wantMessage) {
+				t.Errorf("Message = %q, want it to mention %q", result.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestJudgeRejectsUnavailableInteractor(t *testing.T) {
+	r := newTestRunner(t)
+	job := Job{Language: "go", Code: "x", Interactor: &models.Interactor{Language: "cobol", Source: "x"}}
+	if _, err := r.Judge(context.Background(), job); err == nil {
+		t.Error("Judge accepted an interactor in an unavailable language")
+	}
+}
@@ -0,0 +1,62 @@
+package runner
+
+import (
+	"bytes"
+	"strings"
+)
+
+// limitedBuffer keeps at most limit bytes and records whether more was written.
+// Writes never fail so that a chatty program is not killed by EPIPE.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func newLimitedBuffer(limit int) *limitedBuffer {
+	return &limitedBuffer{limit: limit}
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	remaining := b.limit - b.buf.Len()
+	if len(p) > remaining {
+		b.truncated = true
+		b.buf.Write(p[:max(remaining, 0)])
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
+
+// excerpt returns the beginning of a program's output, cut to at most
+// maxExcerptBytes, as valid UTF-8 without NUL bytes so that it can be stored
+// as text.
+func excerpt(output string) string {
+	cut := len(output) > maxExcerptBytes
+	if cut {
+		output = output[:maxExcerptBytes]
+	}
+	output = strings.ToValidUTF8(strings.ReplaceAll(output, "\x00", ""), "\uFFFD")
+	if cut {
+		// The cut may have split a character into an invalid one.
+		output = strings.TrimSuffix(output, "\uFFFD") + "…"
+	}
+	return output
+}
+
+// outputsMatch compares program output with the expected output, ignoring
+// trailing whitespace on each line and trailing blank lines.
+func outputsMatch(expected, actual string) bool {
+	return normalizeOutput(expected) == normalizeOutput(actual)
+}
+
+func normalizeOutput(output string) string {
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
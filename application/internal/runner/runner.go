@@ -0,0 +1,329 @@
+// Package runner compiles submitted programs and judges them against the test
+// cases of a question inside a resource-limited, network-less sandbox.
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"online-judge/internal/buildcache"
+	"online-judge/internal/config"
+	"online-judge/internal/languages"
+	"online-judge/internal/models"
+)
+
+const (
+	// maxOutputBytes caps how much of a program's stdout is kept for comparison.
+	maxOutputBytes = 16 << 20
+	// maxMessageBytes caps compiler output and stderr kept for error messages.
+	maxMessageBytes = models.MaxMessageBytes
+	// maxExcerptBytes caps the stdout and stderr kept in each test result.
+	maxExcerptBytes = models.MaxExcerptBytes
+	// maxProcesses caps the processes and threads of a program, which is
+	// plenty for runtimes such as the JVM but stops fork bombs.
+	maxProcesses = 64
+)
+
+// Job is a single submission to judge.
+type Job struct {
+	SubmissionID  int
+	Language      string
+	Code          string
+	TestCases     []models.TestCase
+	TimeLimit     time.Duration
+	MemoryLimitMB int
+	// Checker judges the output of each test; the zero Checker ignores
+	// trailing whitespace.
+	Checker models.Checker
+	// Interactor, if set, talks with the program on each test instead of
+	// giving it the test's input, and decides the verdict in place of the
+	// checker.
+	Interactor *models.Interactor
+	// Progress, if set, is called with the number of tests passed so far
+	// after each passing test.
+	Progress func(passed int)
+}
+
+// Result is the outcome of judging a Job.
+type Result struct {
+	Verdict    models.SubmissionResult
+	TimeMS     int    // highest CPU time over all executed tests
+	MemoryMB   int    // highest peak memory over all executed tests
+	Message    string // compiler output or details of the first failure
+	FailedTest int    // 1-based index of the first failing test, 0 if all passed
+	// Tests are the results of the tests that were run, up to the first
+	// failing one.
+	Tests []models.TestResult
+}
+
+// Runner judges jobs, running at most MaxConcurrent of them at a time.
+type Runner struct {
+	// languages are the enabled languages installed on this host, by ID.
+	languages   map[string]languages.Language
+	languageIDs []string
+	// cache, if set, holds the builds of the languages in toolchains, which
+	// maps their IDs to what identifies their compiler.
+	cache      *buildcache.Cache
+	toolchains map[string]string
+	// compilePath is the PATH of compilers, and homes their home
+	// directories by language ID.
+	compilePath    string
+	homes          map[string]string
+	compileTimeout time.Duration
+	maxMemoryMB    int
+	cpuLimit       int
+	slots          chan struct{}
+}
+
+// New creates a Runner from the runner configuration. Enabled languages whose
+// compiler or interpreter is missing on this host are skipped with a warning.
+// It fails if none is left or the host cannot provide the process sandbox.
+func New(cfg config.RunnerConfig) (*Runner, error) {
+	if cfg.MaxConcurrent < 1 {
+		return nil, fmt.Errorf("runner max_concurrent must be at least 1, got %d", cfg.MaxConcurrent)
+	}
+
+	registry, err := languages.NewRegistry(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error configuring languages: %w", err)
+	}
+	r := &Runner{
+		languages:      make(map[string]languages.Language),
+		compilePath:    compilePath(),
+		compileTimeout: cfg.Timeout,
+		maxMemoryMB:    cfg.MemoryLimitMB,
+		cpuLimit:       cfg.CPULimit,
+		slots:          make(chan struct{}, cfg.MaxConcurrent),
+	}
+	for _, language := range registry.All() {
+		language, err := language.Installed(r.compilePath, sandboxPath)
+		if err == nil {
+			err = checkVisible(language)
+		}
+		if err != nil {
+			log.Printf("Warning: not judging %s: %v", language.Name, err)
+			continue
+		}
+		r.languages[language.ID] = language
+		r.languageIDs = append(r.languageIDs, language.ID)
+	}
+	if len(r.languageIDs) == 0 {
+		return nil, errors.New("none of the enabled languages is installed")
+	}
+
+	if err := checkSandbox(); err != nil {
+		return nil, fmt.Errorf("sandbox unavailable: %w", err)
+	}
+	if err := r.prepareHomes(cfg.ToolchainDir); err != nil {
+		return nil, fmt.Errorf("error preparing compiler homes: %w", err)
+	}
+
+	if cfg.BuildCacheMB > 0 {
+		if r.cache, err = buildcache.Open(cfg.BuildCacheDir, int64(cfg.BuildCacheMB)<<20); err != nil {
+			return nil, err
+		}
+		r.toolchains = r.identifyToolchains()
+	}
+	return r, nil
+}
+
+// checkVisible checks that the compiler and interpreter of language, which
+// run in the sandbox, are among what it sees of the host.
+func checkVisible(language languages.Language) error {
+	for _, command := range [][]string{language.Compile, language.Run} {
+		if len(command) > 0 && filepath.IsAbs(command[0]) && !visibleInSandbox(command[0]) {
+			return fmt.Errorf("%s is outside the sandbox's system directories", command[0])
+		}
+	}
+	return nil
+}
+
+// Languages returns the IDs of the languages this runner can judge.
+func (r *Runner) Languages() []string {
+	return r.languageIDs
+}
+
+// Judge compiles job.Code and runs it against every test case in order,
+// stopping at the first failure. The output of each test is judged by the
+// job's checker, or the program talks with the job's interactor. An error
+// is returned only when the job could not be judged at all; a failing
+// program is reported through Result.Verdict.
+func (r *Runner) Judge(ctx context.Context, job Job) (*Result, error) {
+	language, ok := r.languages[job.Language]
+	if !ok {
+		return nil, fmt.Errorf("language %q is not available on this runner", job.Language)
+	}
+	var checkerLanguage, interactorLanguage languages.Language
+	if job.Interactor != nil {
+		if interactorLanguage, ok = r.languages[job.Interactor.Language]; !ok {
+			return nil, fmt.Errorf("interactor language %q is not available on this runner", job.Interactor.Language)
+		}
+	} else {
+		var err error
+		if checkerLanguage, err = r.validateChecker(job.Checker); err != nil {
+			return nil, err
+		}
+	}
+
+	select {
+	case r.slots <- struct{}{}:
+		defer func() { <-r.slots }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	// The directories of the program, checker and interactor live in one
+	// only the runner's user can enter, and each sandbox sees only its own,
+	// so that the program cannot read the files of the checker or
+	// interactor.
+	jobDir, err := os.MkdirTemp("", "judge-")
+	if err != nil {
+		return nil, fmt.Errorf("error creating job directory: %w", err)
+	}
+	defer os.RemoveAll(jobDir)
+
+	workDir := filepath.Join(jobDir, "program")
+	if err := os.Mkdir(workDir, 0o700); err != nil {
+		return nil, fmt.Errorf("error creating work directory: %w", err)
+	}
+	compileOutput, err := r.compile(ctx, workDir, language, job.Code)
+	if errors.Is(err, errCompile) {
+		return &Result{Verdict: models.ResultCompileError, Message: compileOutput}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var checker *customChecker
+	if job.Interactor == nil && job.Checker.Type == models.CheckerCustom {
+		checkerDir := filepath.Join(jobDir, "checker")
+		if err := os.Mkdir(checkerDir, 0o700); err != nil {
+			return nil, fmt.Errorf("error creating checker directory: %w", err)
+		}
+
+		checker, compileOutput, err = r.buildChecker(ctx, checkerDir, checkerLanguage, job.Checker.Source)
+		if errors.Is(err, errCompile) {
+			return &Result{Verdict: models.ResultSystemError, Message: "the checker does not compile:\n" + compileOutput}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var interactor *interactor
+	if job.Interactor != nil {
+		interactorDir := filepath.Join(jobDir, "interactor")
+		if err := os.Mkdir(interactorDir, 0o700); err != nil {
+			return nil, fmt.Errorf("error creating interactor directory: %w", err)
+		}
+
+		interactor, compileOutput, err = r.buildInteractor(ctx, interactorDir, interactorLanguage, job.Interactor.Source, r.limitsFor(job, language))
+		if errors.Is(err, errCompile) {
+			return &Result{Verdict: models.ResultSystemError, Message: "the interactor does not compile:\n" + compileOutput}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return r.runTests(ctx, workDir, language, checker, interactor, job)
+}
+
+func (r *Runner) runTests(ctx context.Context, workDir string, language languages.Language, checker *customChecker, interactor *interactor, job Job) (*Result, error) {
+	limits := r.limitsFor(job, language)
+	result := &Result{Verdict: models.ResultOK}
+
+	for i, testCase := range job.TestCases {
+		execution, verdict, message, err := r.runTest(ctx, workDir, language, limits, checker, interactor, job, testCase)
+		if err != nil {
+			return nil, fmt.Errorf("error judging test %d: %w", i+1, err)
+		}
+
+		test := models.TestResult{
+			TestNumber:      i + 1,
+			IsSample:        testCase.IsSample,
+			Result:          verdict,
+			ExecutionTimeMS: int(execution.CPUTime.Milliseconds()),
+			MemoryUsageMB:   execution.MemoryKB / 1024,
+			ExitCode:        execution.ExitCode,
+			Signal:          execution.Signal,
+			Stdout:          excerpt(execution.Stdout),
+			Stderr:          excerpt(execution.Stderr),
+			Message:         excerpt(message),
+		}
+		result.Tests = append(result.Tests, test)
+		result.TimeMS = max(result.TimeMS, test.ExecutionTimeMS)
+		result.MemoryMB = max(result.MemoryMB, test.MemoryUsageMB)
+
+		if verdict != models.ResultOK {
+			result.Verdict = verdict
+			result.Message = message
+			result.FailedTest = i + 1
+			return result, nil
+		}
+		if job.Progress != nil {
+			job.Progress(i + 1)
+		}
+	}
+	return result, nil
+}
+
+// runTest runs the program on one test, either with the test's input or
+// talking with the interactor, and judges how it went.
+func (r *Runner) runTest(ctx context.Context, workDir string, language languages.Language, limits limits, checker *customChecker, interactor *interactor, job Job, testCase models.TestCase) (*execution, models.SubmissionResult, string, error) {
+	if interactor != nil {
+		return interactor.run(ctx, workDir, language.Run, testCase, limits)
+	}
+
+	execution, err := execute(ctx, invocation{dir: workDir, command: language.Run, limits: limits}, testCase.Input)
+	if err != nil {
+		return nil, "", "", err
+	}
+	verdict, message := judgeExecution(execution, limits)
+	if verdict == models.ResultOK {
+		verdict, message, err = r.check(ctx, job, checker, testCase, execution.Stdout)
+		if err != nil {
+			return nil, "", "", fmt.Errorf("error checking output: %w", err)
+		}
+	}
+	return execution, verdict, message, nil
+}
+
+// limitsFor applies the runner-wide caps to a job's own limits, then adjusts
+// them for the job's language.
+func (r *Runner) limitsFor(job Job, language languages.Language) limits {
+	memoryMB := job.MemoryLimitMB
+	if r.maxMemoryMB > 0 && (memoryMB <= 0 || memoryMB > r.maxMemoryMB) {
+		memoryMB = r.maxMemoryMB
+	}
+	multiplied := math.Round(float64(job.TimeLimit) * language.TimeMultiplier)
+	return limits{
+		Time:      time.Duration(multiplied),
+		MemoryMB:  memoryMB + language.MemoryOverheadMB,
+		CPUs:      r.cpuLimit,
+		Processes: maxProcesses,
+	}
+}
+
+// judgeExecution turns a finished execution into a verdict for one test,
+// which is ok if the program ran within its limits; its output is judged by
+// the checker.
+func judgeExecution(execution *execution, limits limits) (models.SubmissionResult, string) {
+	switch {
+	case execution.TimedOut || execution.CPUTime > limits.Time:
+		return models.ResultTimeLimitExceeded, fmt.Sprintf("time limit of %s exceeded", limits.Time)
+	case execution.MemoryKB > limits.MemoryMB*1024 || execution.OutOfMemory():
+		return models.ResultMemoryLimitExceeded, fmt.Sprintf("memory limit of %d MB exceeded", limits.MemoryMB)
+	case execution.OutputTruncated:
+		return models.ResultRuntimeError, "output limit exceeded"
+	case execution.ExitCode != 0:
+		return models.ResultRuntimeError, execution.Failure()
+	}
+	return models.ResultOK, ""
+}
@@ -0,0 +1,429 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"online-judge/internal/config"
+	"online-judge/internal/languages"
+	"online-judge/internal/models"
+)
+
+// toolchainDir is shared by the runners of all tests, so that compilers such
+// as Go's fill their caches only once.
+var toolchainDir string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "runner-test-toolchains-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	toolchainDir = dir
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func newTestRunner(t *testing.T) *Runner {
+	t.Helper()
+
+	r, err := New(config.RunnerConfig{
+		MaxConcurrent: 2,
+		Timeout:       time.Minute,
+		MemoryLimitMB: 256,
+		CPULimit:      1,
+		// Java is left out as the JVM alone outgrows the test's memory limit
+		EnabledLanguages: []string{"go", "c", "cpp", "python3", "rust"},
+		ToolchainDir:     toolchainDir,
+	})
+	if err != nil {
+		t.Skipf("runner unavailable on this host: %v", err)
+	}
+	return r
+}
+
+func TestJudge(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles and runs programs")
+	}
+	r := newTestRunner(t)
+
+	sumTests := []models.TestCase{
+		{Input: "5 7\n", ExpectedOutput: "12\n"},
+		{Input: "-5 5\n", ExpectedOutput: "0"},
+	}
+
+	tests := []struct {
+		name           string
+		language       string
+		code           string
+		wantVerdict    models.SubmissionResult
+		wantFailedTest int
+	}{
+		{
+			name:     "accepted",
+			language: "go",
+			code: `package main
+import "fmt"
+func main() { var a, b int; fmt.Scan(&a, &b); fmt.Println(a + b) }`,
+			wantVerdict: models.ResultOK,
+		},
+		{
+			name:     "wrong answer on second test",
+			language: "go",
+			code: `package main
+import "fmt"
+func main() { var a, b int; fmt.Scan(&a, &b); fmt.Println(a*b - 23) }`,
+			wantVerdict:    models.ResultWrongAnswer,
+			wantFailedTest: 2,
+		},
+		{
+			name:     "compile error",
+			language: "go",
+			code: `package main
+func main() { undefined() }`,
+			wantVerdict: models.ResultCompileError,
+		},
+		{
+			name:     "runtime error",
+			language: "go",
+			code: `package main
+func main() { var m map[string]int; m["x"] = 1 }`,
+			wantVerdict:    models.ResultRuntimeError,
+			wantFailedTest: 1,
+		},
+		{
+			name:     "time limit exceeded",
+			language: "go",
+			code: `package main
+func main() { for {} }`,
+			wantVerdict:    models.ResultTimeLimitExceeded,
+			wantFailedTest: 1,
+		},
+		{
+			name:     "memory limit exceeded",
+			language: "go",
+			code: `package main
+import "fmt"
+func main() {
+	data := make([][]byte, 0)
+	for i := 0; i < 64; i++ {
+		chunk := make([]byte, 4<<20)
+		for j := range chunk { chunk[j] = 1 }
+		data = append(data, chunk)
+	}
+	fmt.Println(len(data))
+}`,
+			wantVerdict:    models.ResultMemoryLimitExceeded,
+			wantFailedTest: 1,
+		},
+		{
+			name:     "network is unavailable",
+			language: "go",
+			code: `package main
+import ("fmt"; "net")
+func main() {
+	if _, err := net.Dial("tcp", "1.1.1.1:80"); err == nil { fmt.Println("connected") }
+	var a, b int; fmt.Scan(&a, &b); fmt.Println(a + b)
+}`,
+			wantVerdict: models.ResultOK,
+		},
+		{
+			name:     "accepted in C",
+			language: "c",
+			code: `#include <stdio.h>
+int main(void) { long a, b; scanf("%ld %ld", &a, &b); printf("%ld\n", a + b); return 0; }`,
+			wantVerdict: models.ResultOK,
+		},
+		{
+			name:        "compile error in C",
+			language:    "c",
+			code:        `int main(void) { return undefined(); }`,
+			wantVerdict: models.ResultCompileError,
+		},
+		{
+			name:     "accepted in C++",
+			language: "cpp",
+			code: `#include <iostream>
+int main() { long long a, b; std::cin >> a >> b; std::cout << a + b << std::endl; }`,
+			wantVerdict: models.ResultOK,
+		},
+		{
+			name:     "memory limit exceeded in C++",
+			language: "cpp",
+			code: `#include <iostream>
+#include <vector>
+int main() {
+	std::vector<std::vector<char>> data;
+	for (int i = 0; i < 64; i++) data.emplace_back(4 << 20, 1);
+	std::cout << data.size() << std::endl;
+}`,
+			wantVerdict:    models.ResultMemoryLimitExceeded,
+			wantFailedTest: 1,
+		},
+		{
+			name:        "accepted in Python",
+			language:    "python3",
+			code:        "a, b = map(int, input().split())\nprint(a + b)\n",
+			wantVerdict: models.ResultOK,
+		},
+		{
+			name:        "syntax error in Python",
+			language:    "python3",
+			code:        "print(\n",
+			wantVerdict: models.ResultCompileError,
+		},
+		{
+			name:           "runtime error in Python",
+			language:       "python3",
+			code:           "raise SystemExit(3)\n",
+			wantVerdict:    models.ResultRuntimeError,
+			wantFailedTest: 1,
+		},
+		{
+			name:     "accepted in Rust",
+			language: "rust",
+			code: `use std::io::Read;
+fn main() {
+	let mut input = String::new();
+	std::io::stdin().read_to_string(&mut input).unwrap();
+	let sum: i64 = input.split_whitespace().map(|n| n.parse::<i64>().unwrap()).sum();
+	println!("{}", sum);
+}`,
+			wantVerdict: models.ResultOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := r.languages[tt.language]; !ok {
+				t.Skipf("%s is not installed on this host", tt.language)
+			}
+			result, err := r.Judge(context.Background(), Job{
+				Language:      tt.language,
+				Code:          tt.code,
+				TestCases:     sumTests,
+				TimeLimit:     500 * time.Millisecond,
+				MemoryLimitMB: 64,
+			})
+			if err != nil {
+				t.Fatalf("Judge: %v", err)
+			}
+			if result.Verdict != tt.wantVerdict {
+				t.Errorf("Verdict = %s, want %s (message: %s)", result.Verdict, tt.wantVerdict, result.Message)
+			}
+			if result.FailedTest != tt.wantFailedTest {
+				t.Errorf("FailedTest = %d, want %d", result.FailedTest, tt.wantFailedTest)
+			}
+		})
+	}
+}
+
+func TestJudgeReusesCachedBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles and runs programs")
+	}
+	r, err := New(config.RunnerConfig{
+		MaxConcurrent:    1,
+		Timeout:          time.Minute,
+		MemoryLimitMB:    256,
+		CPULimit:         1,
+		EnabledLanguages: []string{"c"},
+		ToolchainDir:     toolchainDir,
+		BuildCacheDir:    filepath.Join(t.TempDir(), "builds"),
+		BuildCacheMB:     16,
+	})
+	if err != nil {
+		t.Skipf("runner unavailable on this host: %v", err)
+	}
+
+	job := Job{
+		Language:      "c",
+		Code:          `#include <stdio.h>` + "\nint main(void) { puts(\"cached\"); return 0; }",
+		TestCases:     []models.TestCase{{ExpectedOutput: "cached"}},
+		TimeLimit:     time.Second,
+		MemoryLimitMB: 64,
+	}
+	for i := 0; i < 2; i++ {
+		result, err := r.Judge(context.Background(), job)
+		if err != nil {
+			t.Fatalf("Judge: %v", err)
+		}
+		if result.Verdict != models.ResultOK {
+			t.Fatalf("Verdict = %s, want ok (message: %s)", result.Verdict, result.Message)
+		}
+	}
+
+	stats, ok := r.BuildCacheStats()
+	if !ok {
+		t.Fatal("build cache is disabled")
+	}
+	if stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("Stats = %+v, want the rejudge to hit the cache", stats)
+	}
+}
+
+func TestJudgeRecordsTestResults(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles and runs programs")
+	}
+	r := newTestRunner(t)
+	if _, ok := r.languages["c"]; !ok {
+		t.Skip("c is not installed on this host")
+	}
+
+	result, err := r.Judge(context.Background(), Job{
+		Language: "c",
+		Code: `#include <stdio.h>
+int main(void) { long n; scanf("%ld", &n); fprintf(stderr, "got %ld", n); printf("%ld\n", n * 2); return n > 1; }`,
+		TestCases: []models.TestCase{
+			{Input: "1", ExpectedOutput: "2", IsSample: true},
+			{Input: "2", ExpectedOutput: "4"},
+			{Input: "3", ExpectedOutput: "6"},
+		},
+		TimeLimit:     time.Second,
+		MemoryLimitMB: 64,
+	})
+	if err != nil {
+		t.Fatalf("Judge: %v", err)
+	}
+
+	if len(result.Tests) != 2 {
+		t.Fatalf("Tests = %+v, want the two tests up to the failing one", result.Tests)
+	}
+	first, second := result.Tests[0], result.Tests[1]
+	if first.TestNumber != 1 || !first.IsSample || first.Result != models.ResultOK ||
+		first.Stdout != "2\n" || first.Stderr != "got 1" || first.ExitCode != 0 {
+		t.Errorf("first test = %+v", first)
+	}
+	if second.TestNumber != 2 || second.IsSample || second.Result != models.ResultRuntimeError ||
+		second.ExitCode != 1 || !strings.Contains(second.Message, "exit code 1") {
+		t.Errorf("second test = %+v", second)
+	}
+	if result.FailedTest != 2 || result.Verdict != models.ResultRuntimeError {
+		t.Errorf("Verdict = %s on test %d, want runtime_error on test 2", result.Verdict, result.FailedTest)
+	}
+}
+
+func TestJudgeReportsCompileTimeout(t *testing.T) {
+	r := newTestRunner(t)
+	r.compileTimeout = time.Second
+	// The compiler stub hangs like a compiler expanding a template bomb.
+	r.languages["slow"] = languages.Language{
+		ID:         "slow",
+		SourceFile: "main.sh",
+		Compile:    []string{"/bin/sh", "-c", "sleep 60"},
+		Run:        []string{"/bin/sh", "main.sh"},
+	}
+
+	started := time.Now()
+	result, err := r.Judge(context.Background(), Job{
+		Language:      "slow",
+		Code:          "echo 1",
+		TestCases:     []models.TestCase{{Input: "", ExpectedOutput: "1\n"}},
+		TimeLimit:     time.Second,
+		MemoryLimitMB: 64,
+	})
+	if err != nil {
+		t.Fatalf("Judge: %v", err)
+	}
+	if result.Verdict != models.ResultCompileError || result.Message != "compilation timed out" {
+		t.Errorf("Judge = %s (%q), want compile_error (\"compilation timed out\")", result.Verdict, result.Message)
+	}
+	if elapsed := time.Since(started); elapsed > 10*time.Second {
+		t.Errorf("Judge took %v, want the compiler killed after about a second", elapsed)
+	}
+}
+
+func TestJudgeRejectsUnknownLanguage(t *testing.T) {
+	r := newTestRunner(t)
+	if _, err := r.Judge(context.Background(), Job{Language: "cobol", Code: "x"}); err == nil {
+		t.Error("Judge accepted a language the runner does not know")
+	}
+}
+
+func TestLimitsFor(t *testing.T) {
+	r := &Runner{maxMemoryMB: 256, cpuLimit: 1}
+	tests := []struct {
+		name     string
+		job      Job
+		language languages.Language
+		want     limits
+	}{
+		{
+			name:     "compiled language keeps the job's limits",
+			job:      Job{TimeLimit: time.Second, MemoryLimitMB: 64},
+			language: languages.Language{TimeMultiplier: 1},
+			want:     limits{Time: time.Second, MemoryMB: 64, CPUs: 1, Processes: maxProcesses},
+		},
+		{
+			name:     "multiplier and overhead are applied",
+			job:      Job{TimeLimit: 500 * time.Millisecond, MemoryLimitMB: 64},
+			language: languages.Language{TimeMultiplier: 3, MemoryOverheadMB: 16},
+			want:     limits{Time: 1500 * time.Millisecond, MemoryMB: 80, CPUs: 1, Processes: maxProcesses},
+		},
+		{
+			name:     "overhead is added after the runner's cap",
+			job:      Job{TimeLimit: time.Second, MemoryLimitMB: 1024},
+			language: languages.Language{TimeMultiplier: 2, MemoryOverheadMB: 64},
+			want:     limits{Time: 2 * time.Second, MemoryMB: 320, CPUs: 1, Processes: maxProcesses},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.limitsFor(tt.job, tt.language); got != tt.want {
+				t.Errorf("limitsFor = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputsMatch(t *testing.T) {
+	tests := []struct {
+		expected string
+		actual   string
+		want     bool
+	}{
+		{"12", "12\n", true},
+		{"a b\nc", "a b  \r\nc\n\n", true},
+		{"12", "13", false},
+		{"a b", "a  b", false},
+		{"1\n2", "1\n\n2", false},
+	}
+
+	for _, tt := range tests {
+		if got := outputsMatch(tt.expected, tt.actual); got != tt.want {
+			t.Errorf("outputsMatch(%q, %q) = %v, want %v", tt.expected, tt.actual, got, tt.want)
+		}
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	long := strings.Repeat("a", maxExcerptBytes-1) + "é"
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{"short output is kept", "42\n", "42\n"},
+		{"long output is cut", strings.Repeat("a", maxExcerptBytes+5), strings.Repeat("a", maxExcerptBytes) + "…"},
+		{"a cut character is dropped", long, strings.Repeat("a", maxExcerptBytes-1) + "…"},
+		{"NUL bytes are removed", "a\x00b", "ab"},
+		{"invalid UTF-8 is replaced", "a\xffb", "a\uFFFDb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := excerpt(tt.output); got != tt.want {
+				t.Errorf("excerpt = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
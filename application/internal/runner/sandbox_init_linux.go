@@ -0,0 +1,425 @@
+//go:build linux
+
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// sandboxInitArg is the name the runner re-executes itself under to become
+// the init process of a sandbox.
+const sandboxInitArg = "online-judge-sandbox-init"
+
+// File descriptors the init process reads its spec from and writes its
+// report to.
+const (
+	sandboxSpecFD   = 3
+	sandboxReportFD = 4
+)
+
+const (
+	// sandboxRoot is where the init process assembles the root of the
+	// sandbox, on a tmpfs mounted over the host's /tmp in its own mount
+	// namespace. Everything taken from the host is opened beforehand.
+	sandboxRoot = "/tmp"
+	// sandboxTmpSize bounds the sandbox's own /tmp.
+	sandboxTmpSize = "64m"
+)
+
+// systemPaths are the host paths, or patterns of them, that sandboxed
+// programs see read-only where they exist: what compilers, interpreters and
+// dynamically linked programs need, and nothing else. Symbolic links among
+// them, such as /bin on merged-/usr systems, are recreated as links.
+var systemPaths = []string{
+	"/bin", "/sbin", "/lib", "/lib32", "/lib64", "/libx32", "/usr",
+	"/etc/alternatives", "/etc/ld.so.cache", "/etc/ld.so.conf", "/etc/ld.so.conf.d",
+	"/etc/localtime", "/etc/java-*",
+}
+
+// visibleInSandbox reports whether path, with its symbolic links resolved,
+// lies within the system paths that sandboxed programs see.
+func visibleInSandbox(path string) bool {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	for dir := resolved; ; dir = filepath.Dir(dir) {
+		for _, pattern := range systemPaths {
+			if matched, _ := filepath.Match(pattern, dir); matched {
+				return true
+			}
+		}
+		if dir == "/" {
+			return false
+		}
+	}
+}
+
+// compilePath is the PATH of sandboxed compilers: the directories of the
+// runner's own PATH that are visible in the sandbox.
+func compilePath() string {
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		resolved, err := filepath.EvalSymlinks(dir)
+		if err != nil || seen[resolved] || !visibleInSandbox(resolved) {
+			continue
+		}
+		seen[resolved] = true
+		dirs = append(dirs, resolved)
+	}
+	return strings.Join(dirs, string(filepath.ListSeparator))
+}
+
+// devices are the device files of the sandbox's /dev.
+var devices = []string{"/dev/null", "/dev/zero", "/dev/full", "/dev/random", "/dev/urandom"}
+
+// sandboxSpec is what the init process of a sandbox is asked to run.
+type sandboxSpec struct {
+	// Dir is the host directory the program runs in, read-only unless
+	// Writable is set.
+	Dir      string
+	Writable bool
+	// Home, if set, is a host directory mounted writable at sandboxHome.
+	Home     string
+	Command  []string
+	Env      []string
+	Limits   limits
+	WallTime time.Duration
+	// Root is set when the runner is root, so that the init process is
+	// outside any user namespace and the program runs as nobody on the host.
+	Root bool
+}
+
+// sandboxReport is how the program ran, or why it could not be run.
+type sandboxReport struct {
+	Execution *execution `json:",omitempty"`
+	Error     string     `json:",omitempty"`
+}
+
+func init() {
+	if len(os.Args) == 1 && os.Args[0] == sandboxInitArg {
+		os.Exit(sandboxInit())
+	}
+}
+
+// sandboxInit is the init process of a sandbox. Its stdin, stdout and stderr
+// are those of the program.
+func sandboxInit() int {
+	var report sandboxReport
+	execution, err := runSandboxed()
+	if err != nil {
+		report.Error = err.Error()
+	} else {
+		report.Execution = execution
+	}
+	if err := json.NewEncoder(os.NewFile(sandboxReportFD, "report")).Encode(report); err != nil {
+		return 1
+	}
+	return 0
+}
+
+func runSandboxed() (*execution, error) {
+	// Keeps the program from reading this process's memory or files
+	// through /proc.
+	if err := unix.Prctl(unix.PR_SET_DUMPABLE, 0, 0, 0, 0); err != nil {
+		return nil, fmt.Errorf("error hiding the sandbox init process: %w", err)
+	}
+
+	var spec sandboxSpec
+	if err := json.NewDecoder(os.NewFile(sandboxSpecFD, "spec")).Decode(&spec); err != nil {
+		return nil, fmt.Errorf("error reading sandbox spec: %w", err)
+	}
+	if err := enterSandboxRoot(spec); err != nil {
+		return nil, fmt.Errorf("error setting up sandbox: %w", err)
+	}
+	if spec.Root {
+		// The program would otherwise keep root's supplementary groups.
+		if err := syscall.Setgroups(nil); err != nil {
+			return nil, fmt.Errorf("error dropping groups: %w", err)
+		}
+	}
+	return runProgram(spec)
+}
+
+// bindMount is a host file or directory to mount in the sandbox, opened
+// before the host's /tmp is hidden.
+type bindMount struct {
+	source   *os.File
+	target   string
+	isDir    bool
+	writable bool
+	device   bool
+}
+
+// enterSandboxRoot builds the sandbox's root file system and pivots into it,
+// leaving the process in sandboxDir.
+func enterSandboxRoot(spec sandboxSpec) error {
+	// Nothing mounted here may reach the host's mount namespace.
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("error making mounts private: %w", err)
+	}
+
+	var mounts []bindMount
+	defer func() {
+		for _, m := range mounts {
+			m.source.Close()
+		}
+	}()
+	addMount := func(source, target string, writable, device bool) error {
+		file, err := os.OpenFile(source, unix.O_PATH|unix.O_CLOEXEC, 0)
+		if err != nil {
+			return err
+		}
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return err
+		}
+		mounts = append(mounts, bindMount{source: file, target: target, isDir: info.IsDir(), writable: writable, device: device})
+		return nil
+	}
+
+	links := make(map[string]string)
+	for _, pattern := range systemPaths {
+		paths, _ := filepath.Glob(pattern)
+		for _, path := range paths {
+			info, err := os.Lstat(path)
+			if err != nil {
+				continue
+			}
+			if info.Mode()&os.ModeSymlink != 0 {
+				target, err := os.Readlink(path)
+				if err != nil {
+					return err
+				}
+				links[path] = target
+				continue
+			}
+			if err := addMount(path, path, false, false); err != nil {
+				return fmt.Errorf("error opening %s: %w", path, err)
+			}
+		}
+	}
+	for _, device := range devices {
+		if err := addMount(device, device, true, true); err != nil {
+			return fmt.Errorf("error opening %s: %w", device, err)
+		}
+	}
+	if err := addMount(spec.Dir, sandboxDir, spec.Writable, false); err != nil {
+		return fmt.Errorf("error opening the program's directory: %w", err)
+	}
+	if spec.Home != "" {
+		if err := addMount(spec.Home, sandboxHome, true, false); err != nil {
+			return fmt.Errorf("error opening the program's home: %w", err)
+		}
+	}
+
+	if err := unix.Mount("tmpfs", sandboxRoot, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, "mode=0755,size=1m"); err != nil {
+		return fmt.Errorf("error mounting root: %w", err)
+	}
+	for path, target := range links {
+		if err := os.MkdirAll(filepath.Dir(sandboxRoot+path), 0o755); err != nil {
+			return err
+		}
+		if err := os.Symlink(target, sandboxRoot+path); err != nil {
+			return err
+		}
+	}
+	for _, m := range mounts {
+		if err := m.mount(sandboxRoot + m.target); err != nil {
+			return fmt.Errorf("error mounting %s: %w", m.target, err)
+		}
+	}
+
+	for path, target := range map[string]string{
+		"/dev/fd": "/proc/self/fd", "/dev/stdin": "/proc/self/fd/0",
+		"/dev/stdout": "/proc/self/fd/1", "/dev/stderr": "/proc/self/fd/2",
+	} {
+		if err := os.Symlink(target, sandboxRoot+path); err != nil {
+			return err
+		}
+	}
+	if err := os.Mkdir(sandboxRoot+"/proc", 0o755); err != nil {
+		return err
+	}
+	if err := unix.Mount("proc", sandboxRoot+"/proc", "proc", unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, ""); err != nil {
+		return fmt.Errorf("error mounting /proc: %w", err)
+	}
+	if err := os.Mkdir(sandboxRoot+"/tmp", 0o755); err != nil {
+		return err
+	}
+	if err := unix.Mount("tmpfs", sandboxRoot+"/tmp", "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, "mode=1777,size="+sandboxTmpSize); err != nil {
+		return fmt.Errorf("error mounting /tmp: %w", err)
+	}
+
+	const oldRoot = "/.old-root"
+	if err := os.Mkdir(sandboxRoot+oldRoot, 0o700); err != nil {
+		return err
+	}
+	if err := unix.PivotRoot(sandboxRoot, sandboxRoot+oldRoot); err != nil {
+		return fmt.Errorf("error pivoting root: %w", err)
+	}
+	if err := os.Chdir("/"); err != nil {
+		return err
+	}
+	if err := unix.Unmount(oldRoot, unix.MNT_DETACH); err != nil {
+		return fmt.Errorf("error detaching the host's root: %w", err)
+	}
+	if err := os.Remove(oldRoot); err != nil {
+		return err
+	}
+	if err := unix.Mount("", "/", "", unix.MS_BIND|unix.MS_REMOUNT|unix.MS_RDONLY|unix.MS_NOSUID|unix.MS_NODEV, ""); err != nil {
+		return fmt.Errorf("error making root read-only: %w", err)
+	}
+	return os.Chdir(sandboxDir)
+}
+
+// mount bind-mounts m at target, creating it first.
+func (m bindMount) mount(target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	if m.isDir {
+		if err := os.Mkdir(target, 0o755); err != nil {
+			return err
+		}
+	} else if err := os.WriteFile(target, nil, 0o644); err != nil {
+		return err
+	}
+
+	source := fmt.Sprintf("/proc/self/fd/%d", m.source.Fd())
+	if err := unix.Mount(source, target, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+		return err
+	}
+
+	// Remounting can only add restrictions to those the host's mount
+	// already has, which a user namespace must keep.
+	var stat unix.Statfs_t
+	if err := unix.Statfs(target, &stat); err != nil {
+		return err
+	}
+	flags := uintptr(unix.MS_BIND | unix.MS_REMOUNT | unix.MS_NOSUID)
+	if !m.writable {
+		flags |= unix.MS_RDONLY
+	}
+	if !m.device {
+		flags |= unix.MS_NODEV
+	}
+	for st, ms := range map[int64]uintptr{
+		unix.ST_RDONLY: unix.MS_RDONLY, unix.ST_NODEV: unix.MS_NODEV, unix.ST_NOEXEC: unix.MS_NOEXEC,
+		unix.ST_NOATIME: unix.MS_NOATIME, unix.ST_NODIRATIME: unix.MS_NODIRATIME, unix.ST_RELATIME: unix.MS_RELATIME,
+	} {
+		if int64(stat.Flags)&st != 0 {
+			flags |= ms
+		}
+	}
+	return unix.Mount("", target, "", flags, "")
+}
+
+// runProgram runs the program of spec within its limits, killing it once its
+// wall-clock time is up, then kills whatever it left behind.
+func runProgram(spec sandboxSpec) (*execution, error) {
+	if len(spec.Command) == 0 {
+		return nil, errors.New("no command to run")
+	}
+	// The program runs as nobody in a user namespace of its own, mapped to
+	// nobody on the host or, without root, to the runner's user, so that it
+	// has no privileges and its processes are counted apart from any other
+	// sandbox's.
+	hostID := 0
+	if spec.Root {
+		hostID = nobodyID
+	}
+	cmd := exec.Command(spec.Command[0], spec.Command[1:]...)
+	cmd.Env = spec.Env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:  syscall.CLONE_NEWUSER,
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: nobodyID, HostID: hostID, Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: nobodyID, HostID: hostID, Size: 1}},
+		Credential:  &syscall.Credential{Uid: nobodyID, Gid: nobodyID, NoSetGroups: true},
+	}
+
+	// The program inherits the limits, which hardly matter to this process.
+	if err := setLimits(spec.Limits); err != nil {
+		return nil, fmt.Errorf("error setting limits: %w", err)
+	}
+
+	var timedOut atomic.Bool
+	started := time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("error starting program: %w", err)
+	}
+	timer := time.AfterFunc(spec.WallTime, func() {
+		timedOut.Store(true)
+		_ = syscall.Kill(-1, syscall.SIGKILL)
+	})
+	waitErr := cmd.Wait()
+	wallTime := time.Since(started)
+	timer.Stop()
+
+	// As init of the PID namespace, this process inherits the program's
+	// orphans, which must not outlive it.
+	_ = syscall.Kill(-1, syscall.SIGKILL)
+	for {
+		if _, err := syscall.Wait4(-1, nil, 0, nil); err != nil && !errors.Is(err, syscall.EINTR) {
+			break
+		}
+	}
+
+	var exitErr *exec.ExitError
+	if waitErr != nil && !errors.As(waitErr, &exitErr) {
+		return nil, fmt.Errorf("error waiting for program: %w", waitErr)
+	}
+	result := &execution{TimedOut: timedOut.Load(), WallTime: wallTime}
+	state := cmd.ProcessState
+	result.ExitCode = state.ExitCode()
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		result.Signal = status.Signal().String()
+		if status.Signal() == syscall.SIGXCPU {
+			result.TimedOut = true
+		}
+	}
+	if usage, ok := state.SysUsage().(*syscall.Rusage); ok {
+		result.CPUTime = time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+		result.MemoryKB = int(usage.Maxrss)
+	}
+	return result, nil
+}
+
+// setLimits applies limits to this process.
+func setLimits(limits limits) error {
+	cpuSeconds := uint64(math.Ceil(limits.Time.Seconds())) + 1
+	// Garbage collectors such as Go's let the heap grow to twice the live
+	// data, and runtimes need some room of their own, so the hard cap is
+	// looser than the judged limit; exceeding the judged limit is detected
+	// from peak RSS.
+	dataBytes := uint64(2*limits.MemoryMB+64) << 20
+	for resource, value := range map[int]uint64{
+		unix.RLIMIT_CPU:   cpuSeconds,
+		unix.RLIMIT_DATA:  dataBytes,
+		unix.RLIMIT_FSIZE: uint64(limits.FileSizeMB) << 20,
+		unix.RLIMIT_CORE:  0,
+		unix.RLIMIT_NPROC: uint64(max(limits.Processes, 1)),
+	} {
+		if err := unix.Setrlimit(resource, &unix.Rlimit{Cur: value, Max: value}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+//go:build linux
+
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// sandboxSetupTime is how long the init process of a sandbox may take on
+// top of the program's wall-clock time before it is killed.
+const sandboxSetupTime = 5 * time.Second
+
+// execute runs command in dir with input on stdin inside the sandbox. The
+// runner re-executes itself as the init process of new mount, PID, network
+// (no interfaces besides a down loopback), IPC and UTS namespaces, which
+// pivots into a minimal read-only root holding the system directories and
+// dir, mounts a /proc of its own, and runs the program as nobody in a user
+// namespace of its own with rlimits:
+//   - RLIMIT_CPU stops runaway computation,
+//   - RLIMIT_DATA bounds heap growth,
+//   - RLIMIT_FSIZE forbids writing files, or bounds them for compilers,
+//   - RLIMIT_CORE disables core dumps,
+//   - RLIMIT_NPROC stops fork bombs.
+//
+// The wall-clock deadline additionally catches programs blocked on sleep or
+// I/O. Whatever the program leaves running dies with the init process.
+func execute(ctx context.Context, program invocation, input string) (*execution, error) {
+	stdout := newLimitedBuffer(maxOutputBytes)
+	p, err := start(ctx, program, bytes.NewReader([]byte(input)), stdout)
+	if err != nil {
+		return nil, err
+	}
+	result, err := p.wait()
+	if err != nil {
+		return nil, err
+	}
+	result.Stdout = stdout.String()
+	result.OutputTruncated = stdout.truncated
+	return result, nil
+}
+
+// interact runs program and interactor side by side in the sandbox, each
+// reading what the other writes to stdout. Either sees the end of its input
+// once the other exits. Neither execution has any Stdout.
+func interact(ctx context.Context, program, interactor invocation) (*execution, *execution, error) {
+	fromProgram, toInteractor, err := os.Pipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating pipe: %w", err)
+	}
+	fromInteractor, toProgram, err := os.Pipe()
+	if err != nil {
+		fromProgram.Close()
+		toInteractor.Close()
+		return nil, nil, fmt.Errorf("error creating pipe: %w", err)
+	}
+	// The children hold their own copies of the pipes; closing ours lets
+	// each see end of file when the other exits.
+	closePipes := func() {
+		for _, pipe := range []*os.File{fromProgram, toInteractor, fromInteractor, toProgram} {
+			pipe.Close()
+		}
+	}
+
+	programProcess, err := start(ctx, program, fromInteractor, toInteractor)
+	if err != nil {
+		closePipes()
+		return nil, nil, err
+	}
+	interactorProcess, err := start(ctx, interactor, fromProgram, toProgram)
+	closePipes()
+	if err != nil {
+		programProcess.kill()
+		_, _ = programProcess.wait()
+		return nil, nil, fmt.Errorf("error starting interactor: %w", err)
+	}
+
+	type outcome struct {
+		execution *execution
+		err       error
+	}
+	interactorDone := make(chan outcome, 1)
+	go func() {
+		execution, err := interactorProcess.wait()
+		interactorDone <- outcome{execution, err}
+	}()
+
+	programExecution, programErr := programProcess.wait()
+	interactorOutcome := <-interactorDone
+	if programErr != nil {
+		return nil, nil, programErr
+	}
+	if interactorOutcome.err != nil {
+		return nil, nil, fmt.Errorf("error waiting for interactor: %w", interactorOutcome.err)
+	}
+	return programExecution, interactorOutcome.execution, nil
+}
+
+// process is a program started in the sandbox.
+type process struct {
+	cmd     *exec.Cmd
+	stderr  *limitedBuffer
+	report  *os.File
+	started time.Time
+	// deadline ends when the init process has used up the program's
+	// wall-clock time and its own setup time.
+	deadline context.Context
+	cancel   context.CancelFunc
+	done     chan error
+}
+
+// start starts a program in the sandbox with the given stdin and stdout.
+func start(ctx context.Context, program invocation, stdin io.Reader, stdout io.Writer) (*process, error) {
+	wallLimit := 2*program.limits.Time + 500*time.Millisecond
+	env := []string{
+		"PATH=" + sandboxPath,
+		"GOMAXPROCS=" + strconv.Itoa(max(program.limits.CPUs, 1)),
+	}
+	spec, err := json.Marshal(sandboxSpec{
+		Dir:      program.dir,
+		Writable: program.writable,
+		Home:     program.home,
+		Command:  program.command,
+		Env:      append(env, program.env...),
+		Limits:   program.limits,
+		WallTime: wallLimit,
+		Root:     os.Geteuid() == 0,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding sandbox spec: %w", err)
+	}
+
+	specReader, specWriter, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("error creating pipe: %w", err)
+	}
+	defer specReader.Close()
+	defer specWriter.Close()
+	reportReader, reportWriter, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("error creating pipe: %w", err)
+	}
+	defer reportWriter.Close()
+
+	deadline, cancel := context.WithTimeout(ctx, wallLimit+sandboxSetupTime)
+	stderr := newLimitedBuffer(maxMessageBytes)
+	cmd := &exec.Cmd{
+		Path:   "/proc/self/exe",
+		Args:   []string{sandboxInitArg},
+		Env:    []string{},
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+		// The init process finds these as sandboxSpecFD and sandboxReportFD.
+		ExtraFiles:  []*os.File{specReader, reportWriter},
+		SysProcAttr: sandboxAttr(),
+	}
+
+	started := time.Now()
+	if err := cmd.Start(); err != nil {
+		cancel()
+		reportReader.Close()
+		return nil, fmt.Errorf("error starting sandbox: %w", err)
+	}
+	p := &process{cmd: cmd, stderr: stderr, report: reportReader, started: started, deadline: deadline, cancel: cancel, done: make(chan error, 1)}
+	go func() { p.done <- cmd.Wait() }()
+
+	// An init process that dies before reading its spec is reported by wait.
+	_, _ = specWriter.Write(spec)
+	return p, nil
+}
+
+// kill kills the init process, and with it everything in the sandbox.
+func (p *process) kill() {
+	_ = syscall.Kill(-p.cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// wait waits for the sandbox to finish, killing it once its time is up, and
+// returns how the program ran as reported by the init process. The execution
+// has no Stdout.
+func (p *process) wait() (*execution, error) {
+	defer p.cancel()
+	defer p.report.Close()
+
+	var waitErr error
+	killed := false
+	select {
+	case waitErr = <-p.done:
+	case <-p.deadline.Done():
+		killed = true
+		p.kill()
+		waitErr = <-p.done
+	}
+	var exitErr *exec.ExitError
+	if waitErr != nil && !errors.As(waitErr, &exitErr) {
+		return nil, fmt.Errorf("error waiting for sandbox: %w", waitErr)
+	}
+
+	var report sandboxReport
+	if err := json.NewDecoder(p.report).Decode(&report); err != nil {
+		if killed {
+			return &execution{
+				Stderr:   p.stderr.String(),
+				Signal:   syscall.SIGKILL.String(),
+				TimedOut: true,
+				WallTime: time.Since(p.started),
+			}, nil
+		}
+		return nil, fmt.Errorf("sandbox exited without a report (%s): %s", p.cmd.ProcessState, p.stderr.String())
+	}
+	if report.Error != "" {
+		return nil, errors.New(report.Error)
+	}
+	result := report.Execution
+	result.Stderr = p.stderr.String()
+	return result, nil
+}
+
+// sandboxAttr starts the init process of a sandbox in new namespaces. As
+// root it keeps its privileges to set the sandbox up; otherwise a user
+// namespace, in which it is root, provides them.
+func sandboxAttr() *syscall.SysProcAttr {
+	attr := &syscall.SysProcAttr{
+		Setpgid:   true,
+		Pdeathsig: syscall.SIGKILL,
+		Cloneflags: syscall.CLONE_NEWNS | syscall.CLONE_NEWPID | syscall.CLONE_NEWNET |
+			syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS,
+	}
+
+	if os.Geteuid() == 0 {
+		return attr
+	}
+
+	attr.Cloneflags |= syscall.CLONE_NEWUSER
+	attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}}
+	attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}}
+	return attr
+}
+
+// ownedByRunner reports whether the file described by info belongs to the
+// runner's user.
+func ownedByRunner(info os.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(stat.Uid) == os.Geteuid()
+}
+
+// checkSandbox verifies that the host allows creating the sandbox.
+func checkSandbox() error {
+	dir, err := os.MkdirTemp("", "sandbox-check-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Chmod(dir, 0o755); err != nil {
+		return err
+	}
+
+	execution, err := execute(context.Background(), invocation{
+		dir:     dir,
+		command: []string{"/bin/sh", "-c", "exit 0"},
+		limits:  limits{Time: time.Second, MemoryMB: 64, Processes: maxProcesses},
+	}, "")
+	if err != nil {
+		return err
+	}
+	if execution.ExitCode != 0 || execution.Signal != "" {
+		return fmt.Errorf("test program failed: %s", execution.Failure())
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+//go:build !linux
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"os"
+)
+
+var errUnsupported = errors.New("the process sandbox requires Linux")
+
+func execute(ctx context.Context, program invocation, input string) (*execution, error) {
+	return nil, errUnsupported
+}
+
+func interact(ctx context.Context, program, interactor invocation) (*execution, *execution, error) {
+	return nil, nil, errUnsupported
+}
+
+func checkSandbox() error {
+	return errUnsupported
+}
+
+func visibleInSandbox(path string) bool {
+	return false
+}
+
+func compilePath() string {
+	return ""
+}
+
+func ownedByRunner(info os.FileInfo) bool {
+	return false
+}
@@ -0,0 +1,145 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"online-judge/internal/models"
+	"online-judge/internal/queue"
+)
+
+// Worker repeatedly claims submissions from a queue, judges them and reports
+// their verdicts. Several workers, in one process or many, may share a queue.
+type Worker struct {
+	id                string
+	source            queue.Source
+	runner            *Runner
+	concurrency       int
+	pollInterval      time.Duration
+	heartbeatInterval time.Duration
+}
+
+// NewWorker creates a Worker that judges up to concurrency submissions at once,
+// polls an empty queue every pollInterval and renews its leases every
+// heartbeatInterval.
+func NewWorker(id string, source queue.Source, runner *Runner, concurrency int, pollInterval, heartbeatInterval time.Duration) *Worker {
+	return &Worker{
+		id:                id,
+		source:            source,
+		runner:            runner,
+		concurrency:       concurrency,
+		pollInterval:      pollInterval,
+		heartbeatInterval: heartbeatInterval,
+	}
+}
+
+// Run judges submissions until ctx is cancelled, then waits for the
+// submissions already being judged to be reported.
+func (w *Worker) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < w.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := w.source.Claim(ctx, w.id, w.runner.Languages())
+		if err != nil {
+			if !errors.Is(err, queue.ErrNoJob) && ctx.Err() == nil {
+				log.Printf("Error claiming submission: %v", err)
+			}
+			w.wait(ctx)
+			continue
+		}
+
+		// A claimed submission is finished even during shutdown, so that it
+		// does not sit in processing until its lease expires.
+		w.process(context.WithoutCancel(ctx), job)
+	}
+}
+
+func (w *Worker) wait(ctx context.Context) {
+	timer := time.NewTimer(w.pollInterval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// process judges one job while keeping its lease alive.
+func (w *Worker) process(ctx context.Context, job *queue.Job) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go w.heartbeat(ctx, cancel, job.SubmissionID)
+
+	log.Printf("Judging submission %d", job.SubmissionID)
+	result, err := w.runner.Judge(ctx, Job{
+		SubmissionID:  job.SubmissionID,
+		Language:      job.Language,
+		Code:          job.Code,
+		TestCases:     job.TestCases,
+		TimeLimit:     time.Duration(job.TimeLimitMS) * time.Millisecond,
+		MemoryLimitMB: job.MemoryLimitMB,
+		Checker:       job.Checker,
+		Interactor:    job.Interactor,
+		Progress: func(passed int) {
+			err := w.source.Progress(ctx, job.SubmissionID, w.id, passed, len(job.TestCases))
+			if err != nil && !errors.Is(err, queue.ErrLeaseLost) && ctx.Err() == nil {
+				log.Printf("Error reporting progress of submission %d: %v", job.SubmissionID, err)
+			}
+		},
+	})
+	if err != nil {
+		// Leave the submission to be reclaimed once its lease expires.
+		log.Printf("Error judging submission %d: %v", job.SubmissionID, err)
+		return
+	}
+
+	verdict := models.Verdict{
+		Result:          result.Verdict,
+		ExecutionTimeMS: result.TimeMS,
+		MemoryUsageMB:   result.MemoryMB,
+		ErrorMessage:    result.Message,
+		FailedTest:      result.FailedTest,
+		Tests:           result.Tests,
+	}
+	if err := w.source.Report(ctx, job.SubmissionID, w.id, verdict); err != nil {
+		log.Printf("Error reporting submission %d: %v", job.SubmissionID, err)
+		return
+	}
+	log.Printf("Submission %d judged: %s", job.SubmissionID, result.Verdict)
+}
+
+// heartbeat renews the lease of a submission until ctx ends, and cancels the
+// judging through abort if the lease has been taken away.
+func (w *Worker) heartbeat(ctx context.Context, abort context.CancelFunc, submissionID int) {
+	ticker := time.NewTicker(w.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := w.source.Heartbeat(ctx, submissionID, w.id)
+			if errors.Is(err, queue.ErrLeaseLost) {
+				log.Printf("Lease of submission %d lost, abandoning it", submissionID)
+				abort()
+				return
+			}
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Error renewing lease of submission %d: %v", submissionID, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package runner
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"online-judge/internal/models"
+	"online-judge/internal/queue"
+)
+
+// fakeSource hands out its jobs once and records the reported verdicts.
+type fakeSource struct {
+	mu       sync.Mutex
+	jobs     []*queue.Job
+	verdicts map[int]models.Verdict
+	reported chan struct{}
+}
+
+func (s *fakeSource) Claim(_ context.Context, _ string, _ []string) (*queue.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.jobs) == 0 {
+		return nil, queue.ErrNoJob
+	}
+	job := s.jobs[0]
+	s.jobs = s.jobs[1:]
+	return job, nil
+}
+
+func (s *fakeSource) Heartbeat(_ context.Context, _ int, _ string) error {
+	return nil
+}
+
+func (s *fakeSource) Progress(_ context.Context, _ int, _ string, _, _ int) error {
+	return nil
+}
+
+func (s *fakeSource) Report(_ context.Context, submissionID int, _ string, verdict models.Verdict) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.verdicts[submissionID] = verdict
+	if len(s.verdicts) == 2 {
+		close(s.reported)
+	}
+	return nil
+}
+
+func TestWorkerJudgesClaimedSubmissions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles and runs programs")
+	}
+	r := newTestRunner(t)
+
+	helloTests := []models.TestCase{{Input: "", ExpectedOutput: "Hello, World!"}}
+	source := &fakeSource{
+		jobs: []*queue.Job{
+			{SubmissionID: 1, Language: "go", Code: "package main\nimport \"fmt\"\nfunc main() { fmt.Println(\"Hello, World!\") }",
+				TimeLimitMS: 1000, MemoryLimitMB: 64, TestCases: helloTests},
+			{SubmissionID: 2, Language: "go", Code: "package main\nfunc main() {", TimeLimitMS: 1000, MemoryLimitMB: 64, TestCases: helloTests},
+		},
+		verdicts: make(map[int]models.Verdict),
+		reported: make(chan struct{}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewWorker("test-worker", source, r, 2, 10*time.Millisecond, time.Second).Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-source.reported:
+	case <-time.After(time.Minute):
+		t.Fatal("timed out waiting for verdicts")
+	}
+	cancel()
+	<-done
+
+	if got := source.verdicts[1].Result; got != models.ResultOK {
+		t.Errorf("submission 1 verdict = %s, want %s", got, models.ResultOK)
+	}
+	if got := source.verdicts[2].Result; got != models.ResultCompileError {
+		t.Errorf("submission 2 verdict = %s, want %s", got, models.ResultCompileError)
+	}
+}
@@ -0,0 +1,72 @@
+// Package stats serves the profile statistics of users, recomputing them
+// only when their submissions have changed.
+package stats
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"online-judge/internal/models"
+	"online-judge/internal/store"
+)
+
+// ActivityDays is how many days of activity, up to and including today,
+// the statistics cover.
+const ActivityDays = 365
+
+// Source computes statistics and tells whether they may have changed.
+// *store.SubmissionStore implements it.
+type Source interface {
+	Stamp(ctx context.Context, userID int) (store.SubmissionStamp, error)
+	UserStats(ctx context.Context, userID int, since time.Time) (*models.UserStats, error)
+}
+
+// Cache keeps the statistics of every user it was asked about. Each lookup
+// costs one index-only query to learn whether the user's submissions changed;
+// the full aggregation runs only when they did, or when a new day starts.
+type Cache struct {
+	source  Source
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[int]entry
+}
+
+type entry struct {
+	stamp store.SubmissionStamp
+	since time.Time
+	stats *models.UserStats
+}
+
+// NewCache creates an empty Cache over source.
+func NewCache(source Source) *Cache {
+	return &Cache{source: source, now: time.Now, entries: make(map[int]entry)}
+}
+
+// Get returns the statistics of a user. The result is shared between
+// callers and must not be modified.
+func (c *Cache) Get(ctx context.Context, userID int) (*models.UserStats, error) {
+	stamp, err := c.source.Stamp(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	today := c.now().UTC().Truncate(24 * time.Hour)
+	since := today.AddDate(0, 0, 1-ActivityDays)
+
+	c.mu.Lock()
+	cached, ok := c.entries[userID]
+	c.mu.Unlock()
+	if ok && cached.stamp == stamp && cached.since.Equal(since) {
+		return cached.stats, nil
+	}
+
+	stats, err := c.source.UserStats(ctx, userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[userID] = entry{stamp: stamp, since: since, stats: stats}
+	c.mu.Unlock()
+	return stats, nil
+}
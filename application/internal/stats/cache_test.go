@@ -0,0 +1,67 @@
+package stats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"online-judge/internal/models"
+	"online-judge/internal/store"
+)
+
+// fakeSource counts how often the statistics are computed.
+type fakeSource struct {
+	stamp    store.SubmissionStamp
+	count    int
+	computed int
+	since    time.Time
+}
+
+func (s *fakeSource) Stamp(_ context.Context, _ int) (store.SubmissionStamp, error) {
+	return s.stamp, nil
+}
+
+func (s *fakeSource) UserStats(_ context.Context, _ int, since time.Time) (*models.UserStats, error) {
+	s.computed++
+	s.since = since
+	return &models.UserStats{Submissions: s.count}, nil
+}
+
+func TestCacheRecomputesOnlyAfterChanges(t *testing.T) {
+	ctx := context.Background()
+	source := &fakeSource{stamp: 1, count: 1}
+	cache := NewCache(source)
+	now := time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	steps := []struct {
+		name         string
+		change       func()
+		wantComputed int
+	}{
+		{"first lookup", func() {}, 1},
+		{"unchanged", func() {}, 1},
+		{"verdict recorded", func() { source.stamp++ }, 2},
+		{"new submission", func() { source.stamp++; source.count++ }, 3},
+		{"later the same day", func() { now = now.Add(8 * time.Hour) }, 3},
+		{"next day", func() { now = now.Add(time.Hour) }, 4},
+	}
+
+	for _, step := range steps {
+		step.change()
+		stats, err := cache.Get(ctx, 7)
+		if err != nil {
+			t.Fatalf("%s: Get: %v", step.name, err)
+		}
+		if source.computed != step.wantComputed {
+			t.Errorf("%s: computed %d times, want %d", step.name, source.computed, step.wantComputed)
+		}
+		if stats.Submissions != source.count {
+			t.Errorf("%s: stale statistics %+v", step.name, stats)
+		}
+	}
+
+	if want := time.Date(2024, 5, 11, 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1-ActivityDays); !source.since.Equal(want) {
+		t.Errorf("activity counted since %s, want %s", source.since, want)
+	}
+}
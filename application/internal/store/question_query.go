@@ -0,0 +1,187 @@
+package store
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"online-judge/internal/models"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// QuestionCursor is a position in the published question list: the sort key
+// of the last question on one page, or the first question on the next.
+type QuestionCursor struct {
+	PublishedAt time.Time
+	ID          int
+}
+
+// String encodes the cursor as an opaque, URL-safe token.
+func (c QuestionCursor) String() string {
+	raw := strconv.FormatInt(c.PublishedAt.UnixMicro(), 10) + "." + strconv.Itoa(c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseQuestionCursor decodes a token produced by QuestionCursor.String.
+func ParseQuestionCursor(token string) (*QuestionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	micros, id, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	usec, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &QuestionCursor{PublishedAt: time.UnixMicro(usec).UTC(), ID: n}, nil
+}
+
+// QuestionQuery selects one page of the published question list. Zero
+// values mean "no filter".
+type QuestionQuery struct {
+	Difficulty string
+	Tags       []string // questions must carry every tag
+	Search     string   // case-insensitive substring of the title
+	// Solved keeps only the questions UserID has (true) or has not (false)
+	// solved. UserID also decides the Solved flag of the returned questions.
+	Solved *bool
+	UserID int
+	Oldest bool // oldest first instead of newest first
+
+	// At most one of After and Before is set: the page after or before that
+	// position in the list.
+	After  *QuestionCursor
+	Before *QuestionCursor
+	Limit  int
+}
+
+// QuestionPage is one page of the published question list.
+type QuestionPage struct {
+	Questions []models.Question
+	HasPrev   bool
+	HasNext   bool
+}
+
+// First returns the cursor of the first question on the page.
+func (p *QuestionPage) First() QuestionCursor {
+	return cursorOf(&p.Questions[0])
+}
+
+// Last returns the cursor of the last question on the page.
+func (p *QuestionPage) Last() QuestionCursor {
+	return cursorOf(&p.Questions[len(p.Questions)-1])
+}
+
+func cursorOf(q *models.Question) QuestionCursor {
+	var publishedAt time.Time
+	if q.PublishedAt != nil {
+		publishedAt = *q.PublishedAt
+	}
+	return QuestionCursor{PublishedAt: publishedAt, ID: q.ID}
+}
+
+// ListPublished returns a page of published questions matching query, using
+// keyset pagination on (published_at, id) so that deep pages cost the same
+// as the first one.
+func (s *QuestionStore) ListPublished(ctx context.Context, query QuestionQuery) (*QuestionPage, error) {
+	sql, args := buildQuestionQuery(query)
+
+	var questions []models.Question
+	if err := s.db.SelectContext(ctx, &questions, sql, args...); err != nil {
+		return nil, fmt.Errorf("error listing published questions: %w", err)
+	}
+
+	// One extra row was fetched to learn whether the list continues
+	more := len(questions) > query.Limit
+	if more {
+		questions = questions[:query.Limit]
+	}
+
+	page := &QuestionPage{Questions: questions}
+	if query.Before != nil {
+		// Rows were read backwards from the cursor; restore display order
+		for i, j := 0, len(questions)-1; i < j; i, j = i+1, j-1 {
+			questions[i], questions[j] = questions[j], questions[i]
+		}
+		page.HasPrev = more
+		page.HasNext = true
+	} else {
+		page.HasPrev = query.After != nil
+		page.HasNext = more
+	}
+	return page, nil
+}
+
+// buildQuestionQuery returns the SQL and arguments for query. It reads
+// Limit+1 rows, walking backwards from Before when that is set.
+func buildQuestionQuery(query QuestionQuery) (string, []any) {
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	solved := `EXISTS (SELECT 1 FROM submissions s
+		WHERE s.question_id = q.id AND s.user_id = ` + arg(query.UserID) + ` AND s.result = 'ok')`
+
+	conditions := []string{"q.status = 'published'"}
+	if query.Difficulty != "" {
+		conditions = append(conditions, "q.difficulty = "+arg(query.Difficulty))
+	}
+	if len(query.Tags) > 0 {
+		conditions = append(conditions, "q.tags @> "+arg(pq.StringArray(query.Tags)))
+	}
+	if query.Search != "" {
+		conditions = append(conditions, "q.title ILIKE "+arg("%"+escapeLike(query.Search)+"%"))
+	}
+	if query.Solved != nil {
+		if *query.Solved {
+			conditions = append(conditions, solved)
+		} else {
+			conditions = append(conditions, "NOT "+solved)
+		}
+	}
+
+	descending := !query.Oldest
+	cursor := query.After
+	if query.Before != nil {
+		descending = !descending
+		cursor = query.Before
+	}
+	order, compare := "ASC", ">"
+	if descending {
+		order, compare = "DESC", "<"
+	}
+	if cursor != nil {
+		conditions = append(conditions, fmt.Sprintf("(q.published_at, q.id) %s (%s, %s)",
+			compare, arg(cursor.PublishedAt), arg(cursor.ID)))
+	}
+
+	sql := `SELECT ` + questionColumns + `, ` + solved + ` AS solved
+		FROM questions q
+		JOIN users u ON u.id = q.owner_id
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY q.published_at ` + order + `, q.id ` + order + `
+		LIMIT ` + arg(query.Limit+1)
+	return sql, args
+}
+
+// escapeLike escapes the wildcard characters of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func TestQuestionCursorRoundTrip(t *testing.T) {
+	cursor := QuestionCursor{PublishedAt: time.Date(2024, 3, 1, 12, 30, 0, 123456000, time.UTC), ID: 42}
+
+	got, err := ParseQuestionCursor(cursor.String())
+	if err != nil {
+		t.Fatalf("ParseQuestionCursor: %v", err)
+	}
+	if !got.PublishedAt.Equal(cursor.PublishedAt) || got.ID != cursor.ID {
+		t.Errorf("round trip = %+v, want %+v", got, cursor)
+	}
+
+	for _, token := range []string{"", "!!!", "bm9kb3Q", "YS4x"} {
+		if _, err := ParseQuestionCursor(token); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("ParseQuestionCursor(%q) error = %v, want ErrInvalidCursor", token, err)
+		}
+	}
+}
+
+func TestBuildQuestionQuery(t *testing.T) {
+	solved := false
+	cursor := &QuestionCursor{PublishedAt: time.Unix(1700000000, 0).UTC(), ID: 7}
+
+	tests := []struct {
+		name     string
+		query    QuestionQuery
+		contains []string
+		args     []any
+	}{
+		{
+			name:     "first page",
+			query:    QuestionQuery{UserID: 3, Limit: 10},
+			contains: []string{"q.status = 'published'", "ORDER BY q.published_at DESC, q.id DESC", "LIMIT $2"},
+			args:     []any{3, 11},
+		},
+		{
+			name: "filters",
+			query: QuestionQuery{
+				UserID: 3, Difficulty: "hard", Tags: []string{"dp"}, Search: "50%_off", Solved: &solved, Limit: 10,
+			},
+			contains: []string{"q.difficulty = $2", "q.tags @> $3", "q.title ILIKE $4", "NOT EXISTS"},
+			args:     []any{3, "hard", pq.StringArray{"dp"}, `%50\%\_off%`, 11},
+		},
+		{
+			name:     "next page",
+			query:    QuestionQuery{UserID: 3, After: cursor, Limit: 10},
+			contains: []string{"(q.published_at, q.id) < ($2, $3)", "ORDER BY q.published_at DESC"},
+			args:     []any{3, cursor.PublishedAt, 7, 11},
+		},
+		{
+			name:     "previous page",
+			query:    QuestionQuery{UserID: 3, Before: cursor, Limit: 10},
+			contains: []string{"(q.published_at, q.id) > ($2, $3)", "ORDER BY q.published_at ASC"},
+			args:     []any{3, cursor.PublishedAt, 7, 11},
+		},
+		{
+			name:     "oldest first, previous page",
+			query:    QuestionQuery{UserID: 3, Oldest: true, Before: cursor, Limit: 10},
+			contains: []string{"(q.published_at, q.id) < ($2, $3)", "ORDER BY q.published_at DESC"},
+			args:     []any{3, cursor.PublishedAt, 7, 11},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := buildQuestionQuery(tt.query)
+			for _, fragment := range tt.contains {
+				if !strings.Contains(sql, fragment) {
+					t.Errorf("query does not contain %q:\n%s", fragment, sql)
+				}
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
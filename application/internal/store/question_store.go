@@ -0,0 +1,193 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"online-judge/internal/models"
+)
+
+const questionColumns = `
+	q.id, q.title, q.statement, q.difficulty, q.tags, q.time_limit_ms, q.memory_limit_mb,
+	q.status, q.owner_id, u.username AS owner_username, q.published_at, q.review_comment,
+	q.checker, q.checker_epsilon, q.checker_language, q.checker_source,
+	q.interactive, q.interactor_language, q.interactor_source, q.created_at, q.updated_at`
+
+// QuestionStore persists questions.
+type QuestionStore struct {
+	db *sqlx.DB
+}
+
+// NewQuestionStore creates a QuestionStore backed by db.
+func NewQuestionStore(db *sqlx.DB) *QuestionStore {
+	return &QuestionStore{db: db}
+}
+
+// Create inserts a new question and fills in its generated ID and timestamps.
+// A question without a checker gets the whitespace checker.
+// Test cases attached to the question are not stored; use CreateWithTestCases.
+func (s *QuestionStore) Create(ctx context.Context, question *models.Question) error {
+	return insertQuestion(ctx, s.db, question)
+}
+
+// CreateWithTestCases inserts a question together with its test cases in a
+// single transaction, so that a failure leaves no partially created question.
+func (s *QuestionStore) CreateWithTestCases(ctx context.Context, question *models.Question) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting question transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertQuestion(ctx, tx, question); err != nil {
+		return err
+	}
+	for i := range question.TestCases {
+		question.TestCases[i].QuestionID = question.ID
+		if err := insertTestCase(ctx, tx, &question.TestCases[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing question %d: %w", question.ID, err)
+	}
+	return nil
+}
+
+// UpdateWithTestCases replaces the content, limits, checker, interactor and
+// test cases of a draft question in a single transaction. It returns ErrConflict if the
+// question is no longer a draft.
+func (s *QuestionStore) UpdateWithTestCases(ctx context.Context, question *models.Question) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting question transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE questions
+		SET title = $2, statement = $3, difficulty = $4, tags = COALESCE($5::text[], '{}'),
+			time_limit_ms = $6, memory_limit_mb = $7, checker = $8, checker_epsilon = $9,
+			checker_language = $10, checker_source = $11, interactive = $12,
+			interactor_language = $13, interactor_source = $14
+		WHERE id = $1 AND status = 'draft'
+		RETURNING updated_at`
+	err = tx.QueryRowxContext(ctx, query,
+		question.ID, question.Title, question.Statement, question.Difficulty, question.Tags,
+		question.TimeLimitMS, question.MemoryLimitMB, question.CheckerType, question.CheckerEpsilon,
+		question.CheckerLanguage, question.CheckerSource, question.Interactive,
+		question.InteractorLanguage, question.InteractorSource,
+	).Scan(&question.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("error updating question %d: %w", question.ID, ErrConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("error updating question %d: %w", question.ID, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM test_cases WHERE question_id = $1`, question.ID); err != nil {
+		return fmt.Errorf("error deleting test cases of question %d: %w", question.ID, err)
+	}
+	for i := range question.TestCases {
+		question.TestCases[i].QuestionID = question.ID
+		if err := insertTestCase(ctx, tx, &question.TestCases[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing question %d: %w", question.ID, err)
+	}
+	return nil
+}
+
+// SetStatus moves a question from one status to another and records the
+// reviewer's comment. Moving to published stamps published_at; moving
+// anywhere else clears it. It returns ErrConflict if the question is not in
+// status from, so concurrent reviews cannot both succeed.
+func (s *QuestionStore) SetStatus(ctx context.Context, id int, from, to models.QuestionStatus, comment string) error {
+	query := `
+		UPDATE questions
+		SET status = $3::question_status,
+			review_comment = $4,
+			published_at = CASE WHEN $3::question_status = 'published' THEN CURRENT_TIMESTAMP END
+		WHERE id = $1 AND status = $2`
+	result, err := s.db.ExecContext(ctx, query, id, from, to, comment)
+	if err != nil {
+		return fmt.Errorf("error moving question %d to %s: %w", id, to, err)
+	}
+	if err := requireRow(result, id); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			err = ErrConflict
+		}
+		return fmt.Errorf("error moving question %d from %s to %s: %w", id, from, to, err)
+	}
+	return nil
+}
+
+func insertQuestion(ctx context.Context, q sqlx.QueryerContext, question *models.Question) error {
+	query := `
+		INSERT INTO questions (title, statement, difficulty, tags, time_limit_ms, memory_limit_mb, status, owner_id,
+			checker, checker_epsilon, checker_language, checker_source,
+			interactive, interactor_language, interactor_source)
+		VALUES ($1, $2, $3, COALESCE($4::text[], '{}'), $5, $6, $7, $8,
+			COALESCE(NULLIF($9::text, '')::question_checker, 'whitespace'), COALESCE(NULLIF($10::double precision, 0), 1e-6),
+			$11, $12, $13, $14, $15)
+		RETURNING id, checker, checker_epsilon, created_at, updated_at`
+
+	err := q.QueryRowxContext(ctx, query,
+		question.Title, question.Statement, question.Difficulty, question.Tags, question.TimeLimitMS,
+		question.MemoryLimitMB, question.Status, question.OwnerID,
+		question.CheckerType, question.CheckerEpsilon, question.CheckerLanguage, question.CheckerSource,
+		question.Interactive, question.InteractorLanguage, question.InteractorSource,
+	).Scan(&question.ID, &question.CheckerType, &question.CheckerEpsilon, &question.CreatedAt, &question.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("error creating question: %w", translateError(err))
+	}
+	return nil
+}
+
+// GetByID returns the question with the given ID, without its test cases.
+func (s *QuestionStore) GetByID(ctx context.Context, id int) (*models.Question, error) {
+	var question models.Question
+	query := `SELECT ` + questionColumns + `
+		FROM questions q
+		JOIN users u ON u.id = q.owner_id
+		WHERE q.id = $1`
+	if err := s.db.GetContext(ctx, &question, query, id); err != nil {
+		return nil, fmt.Errorf("error getting question %d: %w", id, translateError(err))
+	}
+	return &question, nil
+}
+
+// List returns all questions regardless of status, newest first.
+func (s *QuestionStore) List(ctx context.Context) ([]models.Question, error) {
+	var questions []models.Question
+	query := `SELECT ` + questionColumns + `
+		FROM questions q
+		JOIN users u ON u.id = q.owner_id
+		ORDER BY q.created_at DESC, q.id DESC`
+	if err := s.db.SelectContext(ctx, &questions, query); err != nil {
+		return nil, fmt.Errorf("error listing questions: %w", err)
+	}
+	return questions, nil
+}
+
+// ListByOwner returns the questions created by a user in any status, newest first.
+func (s *QuestionStore) ListByOwner(ctx context.Context, ownerID int) ([]models.Question, error) {
+	var questions []models.Question
+	query := `SELECT ` + questionColumns + `
+		FROM questions q
+		JOIN users u ON u.id = q.owner_id
+		WHERE q.owner_id = $1
+		ORDER BY q.created_at DESC, q.id DESC`
+	if err := s.db.SelectContext(ctx, &questions, query, ownerID); err != nil {
+		return nil, fmt.Errorf("error listing questions of user %d: %w", ownerID, err)
+	}
+	return questions, nil
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"online-judge/internal/models"
+)
+
+// SessionStore persists user sessions.
+type SessionStore struct {
+	db *sqlx.DB
+}
+
+// NewSessionStore creates a SessionStore backed by db.
+func NewSessionStore(db *sqlx.DB) *SessionStore {
+	return &SessionStore{db: db}
+}
+
+// Create inserts a new session and fills in its creation time.
+func (s *SessionStore) Create(ctx context.Context, session *models.Session) error {
+	query := `
+		INSERT INTO sessions (id, user_id, user_agent, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at`
+
+	err := s.db.QueryRowxContext(ctx, query,
+		session.ID, session.UserID, session.UserAgent, session.ExpiresAt,
+	).Scan(&session.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error creating session for user %d: %w", session.UserID, translateError(err))
+	}
+	return nil
+}
+
+// GetByID returns the session with the given ID, whether or not it has expired.
+func (s *SessionStore) GetByID(ctx context.Context, id string) (*models.Session, error) {
+	var session models.Session
+	query := `SELECT id, user_id, user_agent, created_at, expires_at FROM sessions WHERE id = $1`
+	if err := s.db.GetContext(ctx, &session, query, id); err != nil {
+		return nil, fmt.Errorf("error getting session: %w", translateError(err))
+	}
+	return &session, nil
+}
+
+// Delete removes a single session.
+func (s *SessionStore) Delete(ctx context.Context, id string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("error deleting session: %w", err)
+	}
+	return nil
+}
+
+// DeleteByUser removes every session of a user.
+func (s *SessionStore) DeleteByUser(ctx context.Context, userID int) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("error deleting sessions of user %d: %w", userID, err)
+	}
+	return nil
+}
+
+// DeleteOthers removes every session of a user except keepID.
+func (s *SessionStore) DeleteOthers(ctx context.Context, userID int, keepID string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE user_id = $1 AND id <> $2`, userID, keepID)
+	if err != nil {
+		return fmt.Errorf("error deleting other sessions of user %d: %w", userID, err)
+	}
+	return nil
+}
+
+// DeleteExpired removes all sessions whose expiry time has passed.
+func (s *SessionStore) DeleteExpired(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE expires_at <= NOW()`); err != nil {
+		return fmt.Errorf("error deleting expired sessions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// Package store provides typed repositories over the PostgreSQL schema
+// defined in the migrations directory.
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrNotFound is returned when a requested row does not exist.
+	ErrNotFound = errors.New("record not found")
+	// ErrDuplicate is returned when an insert or update violates a unique constraint.
+	ErrDuplicate = errors.New("record already exists")
+	// ErrConflict is returned when a conditional update finds the row in a
+	// different state than the caller expected, e.g. a question that is no
+	// longer a draft.
+	ErrConflict = errors.New("record is not in the expected state")
+)
+
+// uniqueViolation is the PostgreSQL error code for unique constraint violations.
+const uniqueViolation = "23505"
+
+// translateError maps driver errors onto the package's sentinel errors.
+func translateError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// requireRow returns ErrNotFound if an update or delete matched no row.
+func requireRow(result sql.Result, id int) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking affected rows: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("record %d: %w", id, ErrNotFound)
+	}
+	return nil
+}
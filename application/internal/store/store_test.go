@@ -0,0 +1,39 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestTranslateError(t *testing.T) {
+	otherErr := errors.New("connection reset")
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"no rows", sql.ErrNoRows, ErrNotFound},
+		{"unique violation", &pq.Error{Code: "23505"}, ErrDuplicate},
+		{"other pq error", &pq.Error{Code: "23503"}, nil},
+		{"unrelated error", otherErr, otherErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := translateError(tt.err)
+			if tt.want == nil {
+				if errors.Is(got, ErrNotFound) || errors.Is(got, ErrDuplicate) {
+					t.Errorf("translateError(%v) = %v, want the original error", tt.err, got)
+				}
+				return
+			}
+			if !errors.Is(got, tt.want) {
+				t.Errorf("translateError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"online-judge/internal/models"
+)
+
+// SubmissionQuery selects one page of submission history, newest first. Zero
+// values mean "no filter".
+type SubmissionQuery struct {
+	UserID     int // 0 lists every user's submissions
+	QuestionID int
+	// Verdict is a submission result such as "wrong_answer", or "pending" or
+	// "processing" for submissions that are not judged yet.
+	Verdict string
+
+	// At most one of After and Before is set: the page after or before the
+	// submission with that ID.
+	After  int
+	Before int
+	Limit  int
+}
+
+// SubmissionPage is one page of submission history.
+type SubmissionPage struct {
+	Submissions []models.Submission
+	HasPrev     bool
+	HasNext     bool
+}
+
+// List returns a page of submissions matching query. Submission IDs grow
+// with their creation time, so the history is paginated by ID alone.
+func (s *SubmissionStore) List(ctx context.Context, query SubmissionQuery) (*SubmissionPage, error) {
+	sql, args := buildSubmissionQuery(query)
+
+	var submissions []models.Submission
+	if err := s.db.SelectContext(ctx, &submissions, sql, args...); err != nil {
+		return nil, fmt.Errorf("error listing submissions: %w", err)
+	}
+
+	// One extra row was fetched to learn whether the list continues
+	more := len(submissions) > query.Limit
+	if more {
+		submissions = submissions[:query.Limit]
+	}
+
+	page := &SubmissionPage{Submissions: submissions}
+	if query.Before != 0 {
+		// Rows were read backwards from the cursor; restore display order
+		for i, j := 0, len(submissions)-1; i < j; i, j = i+1, j-1 {
+			submissions[i], submissions[j] = submissions[j], submissions[i]
+		}
+		page.HasPrev = more
+		page.HasNext = true
+	} else {
+		page.HasPrev = query.After != 0
+		page.HasNext = more
+	}
+	return page, nil
+}
+
+// buildSubmissionQuery returns the SQL and arguments for query. It reads
+// Limit+1 rows, walking backwards from Before when that is set.
+func buildSubmissionQuery(query SubmissionQuery) (string, []any) {
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	conditions := []string{"TRUE"}
+	if query.UserID != 0 {
+		conditions = append(conditions, "s.user_id = "+arg(query.UserID))
+	}
+	if query.QuestionID != 0 {
+		conditions = append(conditions, "s.question_id = "+arg(query.QuestionID))
+	}
+	switch models.SubmissionStatus(query.Verdict) {
+	case "":
+	case models.SubmissionPending, models.SubmissionProcessing:
+		conditions = append(conditions, "s.status = "+arg(query.Verdict)+"::submission_status")
+	default:
+		conditions = append(conditions, "s.result = "+arg(query.Verdict)+"::submission_result")
+	}
+
+	order := "DESC"
+	switch {
+	case query.After != 0:
+		conditions = append(conditions, "s.id < "+arg(query.After))
+	case query.Before != 0:
+		conditions = append(conditions, "s.id > "+arg(query.Before))
+		order = "ASC"
+	}
+
+	sql := `SELECT ` + submissionColumns + submissionTables + `
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY s.id ` + order + `
+		LIMIT ` + arg(query.Limit+1)
+	return sql, args
+}
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildSubmissionQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    SubmissionQuery
+		contains []string
+		args     []any
+	}{
+		{
+			name:     "everyone's first page",
+			query:    SubmissionQuery{Limit: 20},
+			contains: []string{"WHERE TRUE", "ORDER BY s.id DESC", "LIMIT $1"},
+			args:     []any{21},
+		},
+		{
+			name:     "filters",
+			query:    SubmissionQuery{UserID: 3, QuestionID: 9, Verdict: "wrong_answer", Limit: 20},
+			contains: []string{"s.user_id = $1", "s.question_id = $2", "s.result = $3::submission_result"},
+			args:     []any{3, 9, "wrong_answer", 21},
+		},
+		{
+			name:     "not judged yet",
+			query:    SubmissionQuery{UserID: 3, Verdict: "pending", Limit: 20},
+			contains: []string{"s.status = $2::submission_status"},
+			args:     []any{3, "pending", 21},
+		},
+		{
+			name:     "next page",
+			query:    SubmissionQuery{UserID: 3, After: 40, Limit: 20},
+			contains: []string{"s.id < $2", "ORDER BY s.id DESC"},
+			args:     []any{3, 40, 21},
+		},
+		{
+			name:     "previous page",
+			query:    SubmissionQuery{UserID: 3, Before: 40, Limit: 20},
+			contains: []string{"s.id > $2", "ORDER BY s.id ASC"},
+			args:     []any{3, 40, 21},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := buildSubmissionQuery(tt.query)
+			for _, fragment := range tt.contains {
+				if !strings.Contains(sql, fragment) {
+					t.Errorf("query does not contain %q:\n%s", fragment, sql)
+				}
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
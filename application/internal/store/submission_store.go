@@ -0,0 +1,233 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"online-judge/internal/models"
+)
+
+const submissionColumns = `
+	s.id, s.user_id, s.question_id, q.title AS question_title, u.username, s.code, s.language, s.status,
+	s.result, s.error_message, s.execution_time_ms, s.memory_usage_mb, s.failed_test, s.worker_id,
+	s.lease_expires_at, s.attempts, s.created_at, s.updated_at`
+
+const submissionTables = `
+	FROM submissions s
+	JOIN questions q ON q.id = s.question_id
+	JOIN users u ON u.id = s.user_id`
+
+// SubmissionStore persists submissions.
+type SubmissionStore struct {
+	db *sqlx.DB
+}
+
+// NewSubmissionStore creates a SubmissionStore backed by db.
+func NewSubmissionStore(db *sqlx.DB) *SubmissionStore {
+	return &SubmissionStore{db: db}
+}
+
+// Create inserts a new submission and fills in its generated ID and timestamps.
+func (s *SubmissionStore) Create(ctx context.Context, submission *models.Submission) error {
+	query := `
+		INSERT INTO submissions (user_id, question_id, code, language, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at`
+
+	err := s.db.QueryRowxContext(ctx, query,
+		submission.UserID, submission.QuestionID, submission.Code, submission.Language, submission.Status,
+	).Scan(&submission.ID, &submission.CreatedAt, &submission.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("error creating submission: %w", translateError(err))
+	}
+	return nil
+}
+
+// GetByID returns the submission with the given ID.
+func (s *SubmissionStore) GetByID(ctx context.Context, id int) (*models.Submission, error) {
+	var submission models.Submission
+	query := `SELECT ` + submissionColumns + submissionTables + `
+		WHERE s.id = $1`
+	if err := s.db.GetContext(ctx, &submission, query, id); err != nil {
+		return nil, fmt.Errorf("error getting submission %d: %w", id, translateError(err))
+	}
+	return &submission, nil
+}
+
+// ClaimNext atomically hands the oldest pending submission written in one of
+// languages (any language if there are none) to workerID, marking it
+// processing until the lease expires. A submission to a question with a
+// custom checker or an interactor also needs the language of that program
+// among languages.
+// Concurrent callers skip rows locked by each other, so every submission is
+// claimed by exactly one worker.
+// ErrNotFound is returned when no such submission is pending.
+func (s *SubmissionStore) ClaimNext(ctx context.Context, workerID string, languages []string, lease time.Duration) (*models.Submission, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting claim transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.GetContext(ctx, &id, `
+		SELECT s.id FROM submissions s
+		JOIN questions q ON q.id = s.question_id
+		WHERE s.status = 'pending' AND (COALESCE(cardinality($1::text[]), 0) = 0
+			OR (s.language = ANY($1) AND (q.checker <> 'custom' OR q.checker_language = ANY($1))
+				AND (NOT q.interactive OR q.interactor_language = ANY($1))))
+		ORDER BY s.created_at, s.id
+		LIMIT 1
+		FOR UPDATE OF s SKIP LOCKED`,
+		pq.StringArray(languages))
+	if err != nil {
+		return nil, fmt.Errorf("error selecting pending submission: %w", translateError(err))
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE submissions
+		SET status = 'processing', worker_id = $2,
+			lease_expires_at = NOW() + $3 * INTERVAL '1 millisecond'
+		WHERE id = $1`,
+		id, workerID, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("error claiming submission %d: %w", id, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing claim of submission %d: %w", id, err)
+	}
+	return s.GetByID(ctx, id)
+}
+
+// ExtendLease pushes back the lease of a submission still held by workerID.
+// ErrNotFound is returned if the worker no longer holds it.
+func (s *SubmissionStore) ExtendLease(ctx context.Context, id int, workerID string, lease time.Duration) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE submissions
+		SET lease_expires_at = NOW() + $3 * INTERVAL '1 millisecond'
+		WHERE id = $1 AND worker_id = $2 AND status = 'processing'`,
+		id, workerID, lease.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("error extending lease of submission %d: %w", id, err)
+	}
+	return requireRow(result, id)
+}
+
+// Complete records the verdict of a submission held by workerID together
+// with its test results. ErrNotFound is returned if the worker no longer
+// holds it.
+func (s *SubmissionStore) Complete(ctx context.Context, id int, workerID string, verdict models.Verdict) error {
+	var errorMessage *string
+	if verdict.ErrorMessage != "" {
+		errorMessage = &verdict.ErrorMessage
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction for submission %d: %w", id, err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE submissions
+		SET status = 'completed', result = $3, error_message = $4,
+			execution_time_ms = $5, memory_usage_mb = $6, failed_test = NULLIF($7, 0),
+			lease_expires_at = NULL
+		WHERE id = $1 AND worker_id = $2 AND status = 'processing'`,
+		id, workerID, verdict.Result, errorMessage, verdict.ExecutionTimeMS, verdict.MemoryUsageMB,
+		verdict.FailedTest)
+	if err != nil {
+		return fmt.Errorf("error completing submission %d: %w", id, err)
+	}
+	if err := requireRow(result, id); err != nil {
+		return err
+	}
+
+	// Results of an earlier judging of the submission are replaced.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM submission_test_results WHERE submission_id = $1`, id); err != nil {
+		return fmt.Errorf("error deleting test results of submission %d: %w", id, err)
+	}
+	for _, test := range verdict.Tests {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO submission_test_results (submission_id, test_number, is_sample, result,
+				execution_time_ms, memory_usage_mb, exit_code, signal, stdout, stderr, message)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+			id, test.TestNumber, test.IsSample, test.Result, test.ExecutionTimeMS, test.MemoryUsageMB,
+			test.ExitCode, test.Signal, test.Stdout, test.Stderr, test.Message)
+		if err != nil {
+			return fmt.Errorf("error storing test %d of submission %d: %w", test.TestNumber, id, translateError(err))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing submission %d: %w", id, err)
+	}
+	return nil
+}
+
+// ListTestResults returns the test results of a submission in test order.
+func (s *SubmissionStore) ListTestResults(ctx context.Context, id int) ([]models.TestResult, error) {
+	var results []models.TestResult
+	query := `
+		SELECT submission_id, test_number, is_sample, result, execution_time_ms, memory_usage_mb,
+			exit_code, signal, stdout, stderr, message
+		FROM submission_test_results
+		WHERE submission_id = $1
+		ORDER BY test_number`
+	if err := s.db.SelectContext(ctx, &results, query, id); err != nil {
+		return nil, fmt.Errorf("error listing test results of submission %d: %w", id, err)
+	}
+	return results, nil
+}
+
+// ListByResult returns the submissions with the given verdict, newest first.
+func (s *SubmissionStore) ListByResult(ctx context.Context, result models.SubmissionResult) ([]models.Submission, error) {
+	var submissions []models.Submission
+	query := `SELECT ` + submissionColumns + submissionTables + `
+		WHERE s.result = $1
+		ORDER BY s.updated_at DESC, s.id DESC`
+	if err := s.db.SelectContext(ctx, &submissions, query, result); err != nil {
+		return nil, fmt.Errorf("error listing %s submissions: %w", result, err)
+	}
+	return submissions, nil
+}
+
+// ReleasedSubmission is a submission whose runner lease expired, as left by
+// ReleaseExpired: pending again, or completed with a system_error result.
+type ReleasedSubmission struct {
+	ID     int                      `db:"id"`
+	Status models.SubmissionStatus  `db:"status"`
+	Result *models.SubmissionResult `db:"result"`
+}
+
+// ReleaseExpired handles submissions whose runner lease has expired: each
+// one's attempt counter is incremented and it goes back to pending, unless it
+// has now failed maxAttempts times, in which case it is completed with a
+// system_error verdict and leaves the queue. It returns the submissions it
+// released.
+func (s *SubmissionStore) ReleaseExpired(ctx context.Context, maxAttempts int) ([]ReleasedSubmission, error) {
+	var released []ReleasedSubmission
+	err := s.db.SelectContext(ctx, &released, `
+		UPDATE submissions
+		SET attempts = attempts + 1,
+			status = CASE WHEN attempts + 1 >= $1
+				THEN 'completed' ELSE 'pending' END::submission_status,
+			result = CASE WHEN attempts + 1 >= $1
+				THEN 'system_error'::submission_result END,
+			error_message = CASE WHEN attempts + 1 >= $1
+				THEN 'judging failed after ' || (attempts + 1) || ' attempts' END,
+			worker_id = NULL,
+			lease_expires_at = NULL
+		WHERE status = 'processing' AND lease_expires_at < NOW()
+		RETURNING id, status, result`,
+		maxAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("error releasing expired submissions: %w", err)
+	}
+	return released, nil
+}
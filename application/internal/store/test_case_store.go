@@ -0,0 +1,69 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"online-judge/internal/models"
+)
+
+// TestCaseStore persists the test cases of questions.
+type TestCaseStore struct {
+	db *sqlx.DB
+}
+
+// NewTestCaseStore creates a TestCaseStore backed by db.
+func NewTestCaseStore(db *sqlx.DB) *TestCaseStore {
+	return &TestCaseStore{db: db}
+}
+
+// Create inserts a new test case and fills in its generated ID.
+func (s *TestCaseStore) Create(ctx context.Context, testCase *models.TestCase) error {
+	return insertTestCase(ctx, s.db, testCase)
+}
+
+func insertTestCase(ctx context.Context, q sqlx.QueryerContext, testCase *models.TestCase) error {
+	query := `
+		INSERT INTO test_cases (question_id, input, expected_output, is_sample)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`
+
+	err := q.QueryRowxContext(ctx, query,
+		testCase.QuestionID, testCase.Input, testCase.ExpectedOutput, testCase.IsSample,
+	).Scan(&testCase.ID)
+	if err != nil {
+		return fmt.Errorf("error creating test case for question %d: %w", testCase.QuestionID, translateError(err))
+	}
+	return nil
+}
+
+// ListByQuestion returns the test cases of a question in insertion order.
+func (s *TestCaseStore) ListByQuestion(ctx context.Context, questionID int) ([]models.TestCase, error) {
+	var testCases []models.TestCase
+	query := `
+		SELECT id, question_id, input, expected_output, is_sample
+		FROM test_cases
+		WHERE question_id = $1
+		ORDER BY id`
+	if err := s.db.SelectContext(ctx, &testCases, query, questionID); err != nil {
+		return nil, fmt.Errorf("error listing test cases for question %d: %w", questionID, err)
+	}
+	return testCases, nil
+}
+
+// ListSamples returns only the sample test cases of a question, which may be
+// shown to anyone who can see the question.
+func (s *TestCaseStore) ListSamples(ctx context.Context, questionID int) ([]models.TestCase, error) {
+	var testCases []models.TestCase
+	query := `
+		SELECT id, question_id, input, expected_output, is_sample
+		FROM test_cases
+		WHERE question_id = $1 AND is_sample
+		ORDER BY id`
+	if err := s.db.SelectContext(ctx, &testCases, query, questionID); err != nil {
+		return nil, fmt.Errorf("error listing sample test cases for question %d: %w", questionID, err)
+	}
+	return testCases, nil
+}
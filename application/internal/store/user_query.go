@@ -0,0 +1,106 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"online-judge/internal/models"
+)
+
+// UserQuery selects one page of the admin user list, oldest account first.
+// Zero values mean "no filter".
+type UserQuery struct {
+	// Search is a case-insensitive substring of the username, email or full
+	// name.
+	Search string
+	Role   models.Role
+	// Disabled is "disabled" for disabled accounts only, or "active" for the
+	// others.
+	Disabled string
+
+	// At most one of After and Before is set: the page after or before the
+	// user with that ID.
+	After  int
+	Before int
+	Limit  int
+}
+
+// UserPage is one page of the admin user list.
+type UserPage struct {
+	Users   []models.User
+	HasPrev bool
+	HasNext bool
+}
+
+// List returns a page of users matching query.
+func (s *UserStore) List(ctx context.Context, query UserQuery) (*UserPage, error) {
+	sql, args := buildUserQuery(query)
+
+	var users []models.User
+	if err := s.db.SelectContext(ctx, &users, sql, args...); err != nil {
+		return nil, fmt.Errorf("error listing users: %w", err)
+	}
+
+	// One extra row was fetched to learn whether the list continues
+	more := len(users) > query.Limit
+	if more {
+		users = users[:query.Limit]
+	}
+
+	page := &UserPage{Users: users}
+	if query.Before != 0 {
+		// Rows were read backwards from the cursor; restore display order
+		for i, j := 0, len(users)-1; i < j; i, j = i+1, j-1 {
+			users[i], users[j] = users[j], users[i]
+		}
+		page.HasPrev = more
+		page.HasNext = true
+	} else {
+		page.HasPrev = query.After != 0
+		page.HasNext = more
+	}
+	return page, nil
+}
+
+// buildUserQuery returns the SQL and arguments for query. It reads Limit+1
+// rows, walking backwards from Before when that is set.
+func buildUserQuery(query UserQuery) (string, []any) {
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	conditions := []string{"TRUE"}
+	if query.Search != "" {
+		pattern := arg("%" + escapeLike(query.Search) + "%")
+		conditions = append(conditions,
+			"(username ILIKE "+pattern+" OR email ILIKE "+pattern+" OR full_name ILIKE "+pattern+")")
+	}
+	if query.Role != "" {
+		conditions = append(conditions, "role = "+arg(query.Role)+"::user_role")
+	}
+	switch query.Disabled {
+	case "disabled":
+		conditions = append(conditions, "disabled_at IS NOT NULL")
+	case "active":
+		conditions = append(conditions, "disabled_at IS NULL")
+	}
+
+	order := "ASC"
+	switch {
+	case query.After != 0:
+		conditions = append(conditions, "id > "+arg(query.After))
+	case query.Before != 0:
+		conditions = append(conditions, "id < "+arg(query.Before))
+		order = "DESC"
+	}
+
+	sql := `SELECT ` + userColumns + ` FROM users
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY id ` + order + `
+		LIMIT ` + arg(query.Limit+1)
+	return sql, args
+}
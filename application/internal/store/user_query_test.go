@@ -0,0 +1,69 @@
+package store
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"online-judge/internal/models"
+)
+
+func TestBuildUserQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    UserQuery
+		contains []string
+		args     []any
+	}{
+		{
+			name:     "first page",
+			query:    UserQuery{Limit: 25},
+			contains: []string{"WHERE TRUE", "ORDER BY id ASC", "LIMIT $1"},
+			args:     []any{26},
+		},
+		{
+			name:     "search",
+			query:    UserQuery{Search: "50%_off", Limit: 25},
+			contains: []string{"username ILIKE $1 OR email ILIKE $1 OR full_name ILIKE $1"},
+			args:     []any{`%50\%\_off%`, 26},
+		},
+		{
+			name:     "filters",
+			query:    UserQuery{Role: models.RoleAdmin, Disabled: "disabled", Limit: 25},
+			contains: []string{"role = $1::user_role", "disabled_at IS NOT NULL"},
+			args:     []any{models.RoleAdmin, 26},
+		},
+		{
+			name:     "active accounts",
+			query:    UserQuery{Disabled: "active", Limit: 25},
+			contains: []string{"disabled_at IS NULL"},
+			args:     []any{26},
+		},
+		{
+			name:     "next page",
+			query:    UserQuery{After: 40, Limit: 25},
+			contains: []string{"id > $1", "ORDER BY id ASC"},
+			args:     []any{40, 26},
+		},
+		{
+			name:     "previous page",
+			query:    UserQuery{Before: 40, Limit: 25},
+			contains: []string{"id < $1", "ORDER BY id DESC"},
+			args:     []any{40, 26},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := buildUserQuery(tt.query)
+			for _, fragment := range tt.contains {
+				if !strings.Contains(sql, fragment) {
+					t.Errorf("query does not contain %q:\n%s", fragment, sql)
+				}
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
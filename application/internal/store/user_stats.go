@@ -0,0 +1,111 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"online-judge/internal/models"
+)
+
+// SubmissionStamp changes whenever one of a user's submissions is created,
+// updated or deleted, so that statistics computed under one stamp can be
+// reused until it changes.
+type SubmissionStamp int64
+
+// Stamp returns the current SubmissionStamp of a user: the version a trigger
+// bumps with each change to the user's submissions, read by primary key.
+func (s *SubmissionStore) Stamp(ctx context.Context, userID int) (SubmissionStamp, error) {
+	var stamp SubmissionStamp
+	err := s.db.GetContext(ctx, &stamp, `
+		SELECT COALESCE((SELECT version FROM user_stats_versions WHERE user_id = $1), 0)`,
+		userID)
+	if err != nil {
+		return stamp, fmt.Errorf("error stamping submissions of user %d: %w", userID, err)
+	}
+	return stamp, nil
+}
+
+// UserStats aggregates the submissions of a user, counting activity from
+// since onwards.
+func (s *SubmissionStore) UserStats(ctx context.Context, userID int, since time.Time) (*models.UserStats, error) {
+	var totals struct {
+		Submissions int `db:"submissions"`
+		Attempted   int `db:"attempted"`
+		Solved      int `db:"solved"`
+		Accepted    int `db:"accepted"`
+		Judged      int `db:"judged"`
+	}
+	err := s.db.GetContext(ctx, &totals, `
+		SELECT COUNT(*) AS submissions,
+			COUNT(DISTINCT question_id) AS attempted,
+			COUNT(DISTINCT question_id) FILTER (WHERE result = 'ok') AS solved,
+			COUNT(*) FILTER (WHERE result = 'ok') AS accepted,
+			COUNT(*) FILTER (WHERE status = 'completed' AND result <> 'system_error') AS judged
+		FROM submissions
+		WHERE user_id = $1`,
+		userID)
+	if err != nil {
+		return nil, fmt.Errorf("error counting submissions of user %d: %w", userID, err)
+	}
+
+	stats := &models.UserStats{
+		Attempted:          totals.Attempted,
+		Solved:             totals.Solved,
+		Submissions:        totals.Submissions,
+		Accepted:           totals.Accepted,
+		Verdicts:           make(map[models.SubmissionResult]int),
+		SolvedByDifficulty: map[string]int{"easy": 0, "medium": 0, "hard": 0},
+		Activity:           []models.DayActivity{},
+	}
+	if totals.Judged > 0 {
+		stats.AcceptanceRate = float64(totals.Accepted) / float64(totals.Judged)
+	}
+
+	var verdicts []struct {
+		Result models.SubmissionResult `db:"result"`
+		Count  int                     `db:"count"`
+	}
+	err = s.db.SelectContext(ctx, &verdicts, `
+		SELECT result, COUNT(*) AS count
+		FROM submissions
+		WHERE user_id = $1 AND result IS NOT NULL
+		GROUP BY result`,
+		userID)
+	if err != nil {
+		return nil, fmt.Errorf("error counting verdicts of user %d: %w", userID, err)
+	}
+	for _, v := range verdicts {
+		stats.Verdicts[v.Result] = v.Count
+	}
+
+	var difficulties []struct {
+		Difficulty string `db:"difficulty"`
+		Count      int    `db:"count"`
+	}
+	err = s.db.SelectContext(ctx, &difficulties, `
+		SELECT q.difficulty, COUNT(DISTINCT s.question_id) AS count
+		FROM submissions s
+		JOIN questions q ON q.id = s.question_id
+		WHERE s.user_id = $1 AND s.result = 'ok'
+		GROUP BY q.difficulty`,
+		userID)
+	if err != nil {
+		return nil, fmt.Errorf("error counting solved questions of user %d: %w", userID, err)
+	}
+	for _, d := range difficulties {
+		stats.SolvedByDifficulty[d.Difficulty] = d.Count
+	}
+
+	err = s.db.SelectContext(ctx, &stats.Activity, `
+		SELECT to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, COUNT(*) AS submissions
+		FROM submissions
+		WHERE user_id = $1 AND created_at >= $2
+		GROUP BY day
+		ORDER BY day`,
+		userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("error counting daily activity of user %d: %w", userID, err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,187 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"online-judge/internal/models"
+)
+
+const userColumns = `id, username, email, password_hash, full_name, role, disabled_at, created_at, updated_at`
+
+// UserStore persists user accounts.
+type UserStore struct {
+	db *sqlx.DB
+}
+
+// NewUserStore creates a UserStore backed by db.
+func NewUserStore(db *sqlx.DB) *UserStore {
+	return &UserStore{db: db}
+}
+
+// Create inserts a new user and fills in its generated ID and timestamps.
+func (s *UserStore) Create(ctx context.Context, user *models.User) error {
+	query := `
+		INSERT INTO users (username, email, password_hash, full_name, role)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at`
+
+	err := s.db.QueryRowxContext(ctx, query,
+		user.Username, user.Email, user.PasswordHash, user.FullName, user.Role,
+	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("error creating user %q: %w", user.Username, translateError(err))
+	}
+	return nil
+}
+
+// GetByID returns the user with the given ID.
+func (s *UserStore) GetByID(ctx context.Context, id int) (*models.User, error) {
+	var user models.User
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	if err := s.db.GetContext(ctx, &user, query, id); err != nil {
+		return nil, fmt.Errorf("error getting user %d: %w", id, translateError(err))
+	}
+	return &user, nil
+}
+
+// GetByUsername returns the user with the given username.
+func (s *UserStore) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	var user models.User
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = $1`
+	if err := s.db.GetContext(ctx, &user, query, username); err != nil {
+		return nil, fmt.Errorf("error getting user %q: %w", username, translateError(err))
+	}
+	return &user, nil
+}
+
+// Update saves the profile fields of an existing user. The role is left
+// alone, since the user may be a copy loaded before an admin changed it; it
+// changes only through SetRole.
+func (s *UserStore) Update(ctx context.Context, user *models.User) error {
+	query := `
+		UPDATE users
+		SET email = $2, password_hash = $3, full_name = $4
+		WHERE id = $1
+		RETURNING updated_at`
+
+	err := s.db.QueryRowxContext(ctx, query,
+		user.ID, user.Email, user.PasswordHash, user.FullName,
+	).Scan(&user.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("error updating user %d: %w", user.ID, translateError(err))
+	}
+	return nil
+}
+
+// SetRole changes the role of a user on behalf of the admin actorID and
+// records the change in the audit log. An actorID of 0 records a change made
+// from the command line. It returns ErrConflict if the user already has the
+// role.
+func (s *UserStore) SetRole(ctx context.Context, actorID, userID int, role models.Role) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting user transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var old models.Role
+	err = tx.GetContext(ctx, &old, `SELECT role FROM users WHERE id = $1 FOR UPDATE`, userID)
+	if err != nil {
+		return fmt.Errorf("error getting role of user %d: %w", userID, translateError(err))
+	}
+	if old == role {
+		return fmt.Errorf("user %d already is %s: %w", userID, role, ErrConflict)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET role = $2 WHERE id = $1`, userID, role); err != nil {
+		return fmt.Errorf("error changing role of user %d: %w", userID, err)
+	}
+	entry := models.UserAuditEntry{Action: models.AuditRoleChanged, OldValue: string(old), NewValue: string(role)}
+	if err := insertAuditEntry(ctx, tx, actorID, userID, entry); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing role of user %d: %w", userID, err)
+	}
+	return nil
+}
+
+// SetDisabled disables or re-enables a user on behalf of the admin actorID
+// and records the change in the audit log. Disabling also revokes all of the
+// user's sessions. It returns ErrConflict if the account already is in the
+// requested state.
+func (s *UserStore) SetDisabled(ctx context.Context, actorID, userID int, disabled bool) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting user transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var wasDisabled bool
+	err = tx.GetContext(ctx, &wasDisabled, `SELECT disabled_at IS NOT NULL FROM users WHERE id = $1 FOR UPDATE`, userID)
+	if err != nil {
+		return fmt.Errorf("error getting state of user %d: %w", userID, translateError(err))
+	}
+
+	entry := models.UserAuditEntry{Action: models.AuditEnabled}
+	query := `UPDATE users SET disabled_at = NULL WHERE id = $1`
+	if disabled {
+		entry.Action = models.AuditDisabled
+		query = `UPDATE users SET disabled_at = CURRENT_TIMESTAMP WHERE id = $1`
+	}
+	if wasDisabled == disabled {
+		return fmt.Errorf("user %d already is %s: %w", userID, entry.Action, ErrConflict)
+	}
+
+	if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("error updating state of user %d: %w", userID, err)
+	}
+	if disabled {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM sessions WHERE user_id = $1`, userID); err != nil {
+			return fmt.Errorf("error deleting sessions of user %d: %w", userID, err)
+		}
+	}
+	if err := insertAuditEntry(ctx, tx, actorID, userID, entry); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing state of user %d: %w", userID, err)
+	}
+	return nil
+}
+
+func insertAuditEntry(ctx context.Context, tx *sqlx.Tx, actorID, targetID int, entry models.UserAuditEntry) error {
+	query := `
+		INSERT INTO user_audit_log (actor_id, target_id, action, old_value, new_value)
+		VALUES (NULLIF($1, 0), $2, $3, $4, $5)`
+	if _, err := tx.ExecContext(ctx, query, actorID, targetID, entry.Action, entry.OldValue, entry.NewValue); err != nil {
+		return fmt.Errorf("error recording %s of user %d: %w", entry.Action, targetID, err)
+	}
+	return nil
+}
+
+// ListAudit returns the latest limit changes made to user accounts, newest
+// first. A non-zero targetID restricts them to the changes made to that user.
+func (s *UserStore) ListAudit(ctx context.Context, targetID, limit int) ([]models.UserAuditEntry, error) {
+	query := `
+		SELECT a.id, a.actor_id, COALESCE(actor.username, '') AS actor_username,
+			a.target_id, target.username AS target_username,
+			a.action, a.old_value, a.new_value, a.created_at
+		FROM user_audit_log a
+		LEFT JOIN users actor ON actor.id = a.actor_id
+		JOIN users target ON target.id = a.target_id
+		WHERE $1 = 0 OR a.target_id = $1
+		ORDER BY a.id DESC
+		LIMIT $2`
+
+	var entries []models.UserAuditEntry
+	if err := s.db.SelectContext(ctx, &entries, query, targetID, limit); err != nil {
+		return nil, fmt.Errorf("error listing user audit log: %w", err)
+	}
+	return entries, nil
+}
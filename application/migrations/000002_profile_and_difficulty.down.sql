@@ -0,0 +1,4 @@
+ALTER TABLE questions DROP COLUMN IF EXISTS difficulty;
+ALTER TABLE users DROP COLUMN IF EXISTS full_name;
+
+DROP TYPE IF EXISTS question_difficulty;
@@ -0,0 +1,5 @@
+-- Columns used by the web forms that the initial schema did not cover
+CREATE TYPE question_difficulty AS ENUM ('easy', 'medium', 'hard');
+
+ALTER TABLE users ADD COLUMN full_name VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE questions ADD COLUMN difficulty question_difficulty NOT NULL DEFAULT 'easy';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_submissions_pending;
+
+ALTER TABLE submissions DROP COLUMN IF EXISTS lease_expires_at;
+ALTER TABLE submissions DROP COLUMN IF EXISTS worker_id;
@@ -0,0 +1,6 @@
+-- Lease bookkeeping for runners claiming pending submissions
+ALTER TABLE submissions ADD COLUMN worker_id VARCHAR(100);
+ALTER TABLE submissions ADD COLUMN lease_expires_at TIMESTAMP WITH TIME ZONE;
+
+-- Runners pick the oldest pending submission first
+CREATE INDEX idx_submissions_pending ON submissions(created_at, id) WHERE status = 'pending';
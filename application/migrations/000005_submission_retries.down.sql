@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_submissions_lease;
+
+ALTER TABLE submissions DROP COLUMN IF EXISTS attempts;
+
+-- PostgreSQL cannot drop an enum value; re-judge affected submissions instead
+UPDATE submissions SET status = 'pending', result = NULL, error_message = NULL
+WHERE result = 'system_error';
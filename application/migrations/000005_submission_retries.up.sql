@@ -0,0 +1,8 @@
+-- Verdict for submissions that could not be judged after repeated runner failures
+ALTER TYPE submission_result ADD VALUE IF NOT EXISTS 'system_error';
+
+-- Number of claims that ended with an expired lease
+ALTER TABLE submissions ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
+
+-- The reaper looks for processing submissions whose lease has expired
+CREATE INDEX idx_submissions_lease ON submissions(lease_expires_at) WHERE status = 'processing';
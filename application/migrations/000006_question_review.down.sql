@@ -0,0 +1,5 @@
+ALTER TABLE questions DROP COLUMN IF EXISTS review_comment;
+ALTER TABLE questions DROP COLUMN IF EXISTS published_at;
+
+-- PostgreSQL cannot drop an enum value; send questions in review back to their owners
+UPDATE questions SET status = 'draft' WHERE status = 'review';
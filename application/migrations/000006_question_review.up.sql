@@ -0,0 +1,10 @@
+-- Questions wait for an admin's decision between the owner's draft and publication
+ALTER TYPE question_status ADD VALUE IF NOT EXISTS 'review' BEFORE 'published';
+
+-- Set when a question is published and cleared when it is unpublished
+ALTER TABLE questions ADD COLUMN published_at TIMESTAMP WITH TIME ZONE;
+
+-- Comment of the admin who last approved, rejected or unpublished the question
+ALTER TABLE questions ADD COLUMN review_comment TEXT NOT NULL DEFAULT '';
+
+UPDATE questions SET published_at = updated_at WHERE status = 'published';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_submissions_solved;
+DROP INDEX IF EXISTS idx_questions_published;
+DROP INDEX IF EXISTS idx_questions_tags;
+
+ALTER TABLE questions DROP COLUMN IF EXISTS tags;
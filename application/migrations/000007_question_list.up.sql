@@ -0,0 +1,9 @@
+-- Topic labels such as "dp" or "graphs", stored lower case by the question editor
+ALTER TABLE questions ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+CREATE INDEX idx_questions_tags ON questions USING GIN (tags);
+
+-- Keyset pagination of the published question list, newest or oldest first
+CREATE INDEX idx_questions_published ON questions(published_at, id) WHERE status = 'published';
+
+-- Whether a user has solved a question, for the solved/unsolved filter
+CREATE INDEX idx_submissions_solved ON submissions(user_id, question_id) WHERE result = 'ok';
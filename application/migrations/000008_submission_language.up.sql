@@ -0,0 +1,2 @@
+-- Language the submitted code is written in; every submission so far was Go
+ALTER TABLE submissions ADD COLUMN language VARCHAR(20) NOT NULL DEFAULT 'go';
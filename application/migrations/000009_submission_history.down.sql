@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_submissions_user_history;
+
+ALTER TABLE submissions DROP COLUMN IF EXISTS failed_test;
@@ -0,0 +1,5 @@
+-- 1-based index of the first failing test, reported by the runner
+ALTER TABLE submissions ADD COLUMN failed_test INTEGER;
+
+-- Keyset pagination of a user's submission history, newest first
+CREATE INDEX idx_submissions_user_history ON submissions(user_id, id);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_submissions_user_updated;
@@ -0,0 +1,2 @@
+-- Cheap check of whether a user's cached profile statistics are still current
+CREATE INDEX idx_submissions_user_updated ON submissions(user_id, updated_at);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS user_audit_log;
+DROP TYPE IF EXISTS user_audit_action;
+
+ALTER TABLE users DROP COLUMN IF EXISTS disabled_at;
@@ -0,0 +1,18 @@
+-- Set while an admin has disabled the account; disabled users cannot sign in
+ALTER TABLE users ADD COLUMN disabled_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TYPE user_audit_action AS ENUM ('role_changed', 'disabled', 'enabled');
+
+-- Changes admins made to user accounts; rows outlive the admin who made them
+CREATE TABLE user_audit_log (
+    id SERIAL PRIMARY KEY,
+    actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    target_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action user_audit_action NOT NULL,
+    old_value VARCHAR(50) NOT NULL DEFAULT '',
+    new_value VARCHAR(50) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- History of a single account on the user management page
+CREATE INDEX idx_user_audit_log_target_id ON user_audit_log(target_id, id);
@@ -0,0 +1,7 @@
+ALTER TABLE questions DROP CONSTRAINT IF EXISTS questions_custom_checker_check;
+ALTER TABLE questions DROP COLUMN IF EXISTS checker_source;
+ALTER TABLE questions DROP COLUMN IF EXISTS checker_language;
+ALTER TABLE questions DROP COLUMN IF EXISTS checker_epsilon;
+ALTER TABLE questions DROP COLUMN IF EXISTS checker;
+
+DROP TYPE IF EXISTS question_checker;
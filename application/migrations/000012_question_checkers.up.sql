@@ -0,0 +1,17 @@
+CREATE TYPE question_checker AS ENUM (
+    'exact', 'whitespace', 'tokens', 'float', 'case_insensitive', 'unordered_lines', 'custom'
+);
+
+-- How output is compared with the expected output; existing questions keep the
+-- comparison used so far, which ignores trailing whitespace
+ALTER TABLE questions ADD COLUMN checker question_checker NOT NULL DEFAULT 'whitespace';
+
+-- Largest absolute or relative error the float checker accepts
+ALTER TABLE questions ADD COLUMN checker_epsilon DOUBLE PRECISION NOT NULL DEFAULT 1e-6;
+
+-- Custom checker program, compiled and run by the runners like a submission
+ALTER TABLE questions ADD COLUMN checker_language VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE questions ADD COLUMN checker_source TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE questions ADD CONSTRAINT questions_custom_checker_check
+    CHECK (checker <> 'custom' OR (checker_language <> '' AND checker_source <> ''));
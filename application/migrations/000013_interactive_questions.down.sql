@@ -0,0 +1,4 @@
+ALTER TABLE questions DROP CONSTRAINT IF EXISTS questions_interactor_check;
+ALTER TABLE questions DROP COLUMN IF EXISTS interactor_source;
+ALTER TABLE questions DROP COLUMN IF EXISTS interactor_language;
+ALTER TABLE questions DROP COLUMN IF EXISTS interactive;
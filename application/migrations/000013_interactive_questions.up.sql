@@ -0,0 +1,10 @@
+-- Interactive questions are judged by an interactor program that talks with
+-- the submission over its stdin and stdout instead of comparing its output
+ALTER TABLE questions ADD COLUMN interactive BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Interactor program, compiled and run by the runners like a submission
+ALTER TABLE questions ADD COLUMN interactor_language VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE questions ADD COLUMN interactor_source TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE questions ADD CONSTRAINT questions_interactor_check
+    CHECK (NOT interactive OR (interactor_language <> '' AND interactor_source <> ''));
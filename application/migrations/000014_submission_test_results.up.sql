@@ -0,0 +1,20 @@
+-- Outcome of each test a submission was run on; judging stops at the first
+-- failing test, so later tests have no row
+CREATE TABLE submission_test_results (
+    submission_id INTEGER NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+    -- 1-based position of the test among the question's test cases
+    test_number INTEGER NOT NULL CHECK (test_number > 0),
+    -- Whether the test was a sample when judged; other tests are hidden
+    is_sample BOOLEAN NOT NULL DEFAULT false,
+    result submission_result NOT NULL,
+    execution_time_ms INTEGER NOT NULL DEFAULT 0,
+    memory_usage_mb INTEGER NOT NULL DEFAULT 0,
+    exit_code INTEGER NOT NULL DEFAULT 0,
+    signal VARCHAR(50) NOT NULL DEFAULT '',
+    -- Beginnings of the program's output, cut by the runner
+    stdout TEXT NOT NULL DEFAULT '',
+    stderr TEXT NOT NULL DEFAULT '',
+    -- Checker, interactor or runner message about the test
+    message TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (submission_id, test_number)
+);
@@ -0,0 +1,4 @@
+CREATE INDEX IF NOT EXISTS idx_submissions_user_updated ON submissions(user_id, updated_at);
+DROP TRIGGER IF EXISTS bump_submissions_user_stats_version ON submissions;
+DROP FUNCTION IF EXISTS bump_user_stats_version();
+DROP TABLE IF EXISTS user_stats_versions;
@@ -0,0 +1,37 @@
+-- Version of each user's submissions, bumped by every change to them. Unlike
+-- the latest updated_at, which is taken when a transaction starts, it moves
+-- with every commit, so cached profile statistics cannot miss a change that
+-- commits after a later one
+CREATE TABLE user_stats_versions (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    version BIGINT NOT NULL DEFAULT 0
+);
+
+INSERT INTO user_stats_versions (user_id, version)
+SELECT DISTINCT user_id, 1 FROM submissions;
+
+CREATE OR REPLACE FUNCTION bump_user_stats_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO user_stats_versions (user_id, version)
+        VALUES (NEW.user_id, 1)
+        ON CONFLICT (user_id) DO UPDATE SET version = user_stats_versions.version + 1;
+    ELSE
+        -- Rows of deleted users are already gone with them
+        UPDATE user_stats_versions SET version = version + 1 WHERE user_id = OLD.user_id;
+        IF TG_OP = 'UPDATE' AND NEW.user_id <> OLD.user_id THEN
+            UPDATE user_stats_versions SET version = version + 1 WHERE user_id = NEW.user_id;
+        END IF;
+    END IF;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER bump_submissions_user_stats_version
+    AFTER INSERT OR UPDATE OR DELETE ON submissions
+    FOR EACH ROW
+    EXECUTE FUNCTION bump_user_stats_version();
+
+-- The versions replace the check of the latest updated_at
+DROP INDEX IF EXISTS idx_submissions_user_updated;
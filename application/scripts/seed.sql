@@ -1,17 +1,20 @@
+-- Sample users cannot sign in: '!' is not a bcrypt hash, so no password
+-- matches it. Create an admin with create-admin instead.
+
 -- Insert sample admin user
 INSERT INTO users (username, email, password_hash, role) VALUES
-('admin', 'admin@example.com', '$2a$10$X7J3QZq3YQZq3YQZq3YQZq3YQZq3YQZq3YQZq3YQZq3YQZq3YQZq3YQZ', 'admin');
+('admin', 'admin@example.com', '!', 'admin');
 
 -- Insert sample regular users
 INSERT INTO users (username, email, password_hash, role) VALUES
-('user1', 'user1@example.com', '$2a$10$X7J3QZq3YQZq3YQZq3YQZq3YQZq3YQZq3YQZq3YQZq3YQZq3YQZq3YQZ', 'regular'),
-('user2', 'user2@example.com', '$2a$10$X7J3QZq3YQZq3YQZq3YQZq3YQZq3YQZq3YQZq3YQZq3YQZq3YQZq3YQZ', 'regular');
+('user1', 'user1@example.com', '!', 'regular'),
+('user2', 'user2@example.com', '!', 'regular');
 
 -- Insert sample questions
-INSERT INTO questions (title, statement, time_limit_ms, memory_limit_mb, status, owner_id) VALUES
-('Hello World', 'Write a program that prints "Hello, World!"', 1000, 256, 'published', 1),
-('Sum of Two Numbers', 'Write a program that takes two numbers as input and prints their sum', 1000, 256, 'published', 1),
-('Factorial', 'Write a program that calculates the factorial of a given number', 1000, 256, 'draft', 2);
+INSERT INTO questions (title, statement, time_limit_ms, memory_limit_mb, status, owner_id, published_at, tags) VALUES
+('Hello World', 'Write a program that prints "Hello, World!"', 1000, 256, 'published', 1, CURRENT_TIMESTAMP, '{basics}'),
+('Sum of Two Numbers', 'Write a program that takes two numbers as input and prints their sum', 1000, 256, 'published', 1, CURRENT_TIMESTAMP, '{basics,math}'),
+('Factorial', 'Write a program that calculates the factorial of a given number', 1000, 256, 'draft', 2, NULL, '{math,recursion}');
 
 -- Insert test cases for Hello World
 INSERT INTO test_cases (question_id, input, expected_output, is_sample) VALUES